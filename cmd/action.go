@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"time"
 
+	"github.com/itsubaki/hermes/pkg/cache"
 	"github.com/itsubaki/hermes/pkg/hermes"
 	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
 	"github.com/itsubaki/hermes/pkg/usage"
 
 	"github.com/urfave/cli"
@@ -31,8 +34,40 @@ func Action(c *cli.Context) {
 		return
 	}
 
+	reservation := make([]storage.Reservation, 0)
 	for _, p := range purchase {
-		q, _ := hermes.BreakEvenPoint(p.Quantity, p.Price)
+		q, price := hermes.BreakEvenPoint(p.Quantity, p.Price)
 		fmt.Println(q)
+
+		reservation = append(reservation, storage.Reservation{Quantity: q, Price: price})
+	}
+
+	if c.GlobalString("storage") != "sqlite" {
+		return
+	}
+
+	dir := c.GlobalString("dir")
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	s, err := storage.NewSQLite(storage.SQLitePath(dir))
+	if err != nil {
+		fmt.Printf("new sqlite: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	today := time.Now().Format("2006-01-02")
+	for i := range reservation {
+		reservation[i].Date = today
+	}
+
+	if err := s.WriteReservation(today, reservation); err != nil {
+		fmt.Printf("write reservation: %v\n", err)
+		os.Exit(1)
 	}
 }
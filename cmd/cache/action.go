@@ -0,0 +1,499 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+	"github.com/urfave/cli"
+)
+
+type Entry struct {
+	Kind          string   `json:"kind"`
+	Name          string   `json:"name"`
+	Path          string   `json:"path"`
+	Size          int64    `json:"size"`
+	ModTime       string   `json:"mod_time"`
+	SHA256        string   `json:"sha256"`
+	SchemaVersion int      `json:"schema_version"`
+	HermesVersion string   `json:"hermes_version,omitempty"`
+	CreatedAt     string   `json:"created_at,omitempty"`
+	Source        string   `json:"source,omitempty"`
+	Compressed    bool     `json:"compressed"`
+	Rows          int      `json:"rows"`
+	Version       []string `json:"version,omitempty"`
+	Error         string   `json:"error,omitempty"`
+	Verified      bool     `json:"verified"`
+}
+
+func (e Entry) ID() string {
+	return fmt.Sprintf("%s/%s", e.Kind, e.Name)
+}
+
+func List(c *cli.Context) {
+	dir := c.GlobalString("dir")
+	format := c.String("format")
+	if format != "json" && format != "csv" {
+		fmt.Printf("unknown format: %s\n", format)
+		os.Exit(1)
+	}
+
+	entry, err := Scan(dir)
+	if err != nil {
+		fmt.Printf("scan %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	for i := range entry {
+		entry[i] = Inspect(dir, entry[i])
+	}
+
+	if format == "json" {
+		for _, e := range entry {
+			bytes, err := json.Marshal(e)
+			if err != nil {
+				fmt.Printf("marshal: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+		}
+		return
+	}
+
+	if format == "csv" {
+		fmt.Println("kind, name, size, mod_time, schema_version, hermes_version, created_at, source, compressed, rows, version, sha256, error")
+		for _, e := range entry {
+			fmt.Printf(
+				"%s, %s, %d, %s, %d, %s, %s, %s, %t, %d, %s, %s, %s\n",
+				e.Kind,
+				e.Name,
+				e.Size,
+				e.ModTime,
+				e.SchemaVersion,
+				e.HermesVersion,
+				e.CreatedAt,
+				e.Source,
+				e.Compressed,
+				e.Rows,
+				strings.Join(e.Version, " "),
+				e.SHA256,
+				e.Error,
+			)
+		}
+		return
+	}
+}
+
+func Verify(c *cli.Context) {
+	dir := c.GlobalString("dir")
+
+	entry, err := Scan(dir)
+	if err != nil {
+		fmt.Printf("scan %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	corrupted := 0
+	for _, e := range entry {
+		e = Inspect(dir, e)
+		if !e.Verified {
+			corrupted++
+			fmt.Printf("corrupted: %s: %s\n", e.Path, e.Error)
+			continue
+		}
+
+		fmt.Printf("ok: %s (%d rows)\n", e.Path, e.Rows)
+	}
+
+	if corrupted > 0 {
+		fmt.Printf("%d of %d files corrupted\n", corrupted, len(entry))
+		os.Exit(1)
+	}
+}
+
+// Temporary returns the temporary files left by cache.WriteFile in any directory under dir.
+func Temporary(dir string) ([]string, error) {
+	out := make([]string, 0)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		name := info.Name()
+		if strings.HasPrefix(name, ".") && strings.HasSuffix(name, ".tmp") {
+			out = append(out, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk %s: %v", dir, err)
+	}
+
+	return out, nil
+}
+
+func Prune(c *cli.Context) {
+	dir := c.GlobalString("dir")
+	dryrun := c.Bool("dry-run")
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	entry, err := Scan(dir)
+	if err != nil {
+		fmt.Printf("scan %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	tmp, err := Temporary(dir)
+	if err != nil {
+		fmt.Printf("temporary: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, t := range tmp {
+		if dryrun {
+			fmt.Printf("would remove: %s (temporary)\n", t)
+			continue
+		}
+
+		if err := os.Remove(t); err != nil {
+			fmt.Printf("remove %s: %v\n", t, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("remove: %s (temporary)\n", t)
+	}
+
+	older := c.Int("older-than")
+	if older < 0 {
+		fmt.Printf("invalid older-than: %d\n", older)
+		os.Exit(1)
+	}
+
+	before := ""
+	if older > 0 {
+		before = time.Now().AddDate(0, -older, 0).Format("2006-01")
+	}
+
+	orphaned, err := Orphaned(dir, entry)
+	if err != nil {
+		fmt.Printf("orphaned: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, e := range entry {
+		reason := ""
+		if (e.Kind == "usage" || e.Kind == "dimension") && len(before) > 0 && e.Name < before {
+			reason = fmt.Sprintf("older than %d months", older)
+		}
+
+		if orphaned[e.ID()] {
+			reason = "orphaned"
+		}
+
+		if len(reason) < 1 {
+			if e = Inspect(dir, e); !e.Verified {
+				reason = "corrupted"
+			}
+		}
+
+		if len(reason) < 1 {
+			continue
+		}
+
+		if dryrun {
+			fmt.Printf("would remove: %s (%s)\n", e.Path, reason)
+			continue
+		}
+
+		if err := os.Remove(e.Path); err != nil {
+			fmt.Printf("remove %s: %v\n", e.Path, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("remove: %s (%s)\n", e.Path, reason)
+	}
+}
+
+func Remove(c *cli.Context) {
+	dir := c.GlobalString("dir")
+
+	if !c.Args().Present() {
+		fmt.Println("usage: hermes cache rm [pricing/<region>|history/<region>|usage/<YYYY-MM>|dimension/<YYYY-MM>]...")
+		os.Exit(1)
+	}
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	entry, err := Scan(dir)
+	if err != nil {
+		fmt.Printf("scan %s: %v\n", dir, err)
+		os.Exit(1)
+	}
+
+	for _, id := range c.Args() {
+		found := false
+		for _, e := range entry {
+			if e.ID() != id && !strings.HasPrefix(e.ID(), id+"/") {
+				continue
+			}
+
+			if err := os.Remove(e.Path); err != nil {
+				fmt.Printf("remove %s: %v\n", e.Path, err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("remove: %s\n", e.Path)
+			found = true
+		}
+
+		if !found {
+			fmt.Printf("not found: %s\n", id)
+		}
+	}
+}
+
+// Scan returns the pricing, history, usage and dimension cache files in dir.
+func Scan(dir string) ([]Entry, error) {
+	out := make([]Entry, 0)
+	for _, kind := range []string{"pricing", "usage", "dimension"} {
+		path := fmt.Sprintf("%s/%s", dir, kind)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read dir %s: %v", path, err)
+		}
+
+		for _, f := range files {
+			if f.IsDir() || filepath.Ext(f.Name()) != ".out" {
+				continue
+			}
+
+			out = append(out, Entry{
+				Kind:    kind,
+				Name:    strings.TrimSuffix(f.Name(), ".out"),
+				Path:    fmt.Sprintf("%s/%s", path, f.Name()),
+				Size:    f.Size(),
+				ModTime: f.ModTime().Format("2006-01-02 15:04:05"),
+			})
+		}
+	}
+
+	history := fmt.Sprintf("%s/pricing/history", dir)
+	if _, err := os.Stat(history); os.IsNotExist(err) {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+		return out, nil
+	}
+
+	if err := filepath.Walk(history, func(path string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if f.IsDir() || filepath.Ext(f.Name()) != ".out" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(history, path)
+		if err != nil {
+			return err
+		}
+
+		out = append(out, Entry{
+			Kind:    "history",
+			Name:    strings.TrimSuffix(filepath.ToSlash(rel), ".out"),
+			Path:    path,
+			Size:    f.Size(),
+			ModTime: f.ModTime().Format("2006-01-02 15:04:05"),
+		})
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walk %s: %v", history, err)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID() < out[j].ID() })
+	return out, nil
+}
+
+// Orphaned returns the archived versions of history no snapshot of the region includes.
+func Orphaned(dir string, entry []Entry) (map[string]bool, error) {
+	listed := make(map[string]bool)
+	for _, e := range entry {
+		name := strings.Split(e.Name, "/")
+		if e.Kind != "history" || len(name) != 3 || name[1] != "version" {
+			continue
+		}
+
+		if _, ok := listed[name[0]]; ok {
+			continue
+		}
+		listed[name[0]] = true
+
+		snapshot, err := pricing.ListSnapshot(dir, name[0])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range snapshot {
+			version, err := pricing.SnapshotVersion(dir, name[0], s)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot %s/%s: %v", name[0], s, err)
+			}
+
+			for _, v := range version {
+				listed[fmt.Sprintf("%s/version/%s", name[0], v)] = true
+			}
+		}
+	}
+
+	out := make(map[string]bool)
+	for _, e := range entry {
+		name := strings.Split(e.Name, "/")
+		if e.Kind != "history" || len(name) != 3 || name[1] != "version" || listed[e.Name] {
+			continue
+		}
+
+		out[e.ID()] = true
+	}
+
+	return out, nil
+}
+
+// Inspect reads the entry through its Deserialize and fills in checksum, rows and versions.
+func Inspect(dir string, e Entry) Entry {
+	read, err := ioutil.ReadFile(e.Path)
+	if err != nil {
+		e.Error = fmt.Sprintf("read %s: %v", e.Path, err)
+		return e
+	}
+
+	sha := sha256.Sum256(read)
+	e.SHA256 = hex.EncodeToString(sha[:])
+
+	h, _, err := cache.Decode(read)
+	if err != nil {
+		e.Error = fmt.Sprintf("decode %s: %v", e.Path, err)
+		return e
+	}
+
+	e.SchemaVersion = h.SchemaVersion
+	e.HermesVersion = h.HermesVersion
+	e.CreatedAt = h.CreatedAt
+	e.Source = h.Source
+	e.Compressed = h.Compressed
+
+	if e.Kind == "history" && strings.Contains(e.Name, "/snapshot/") {
+		version, err := pricing.DecodeVersion(read)
+		if err != nil {
+			e.Error = fmt.Sprintf("decode %s: %v", e.Path, err)
+			return e
+		}
+
+		e.Version = version
+		e.Verified = true
+		return e
+	}
+
+	if e.Kind == "history" {
+		price, err := pricing.Decode(read)
+		if err != nil {
+			e.Error = fmt.Sprintf("decode %s: %v", e.Path, err)
+			return e
+		}
+
+		e.Version = pricing.Version(price)
+		e.Rows = len(price)
+		e.Verified = true
+		return e
+	}
+
+	if e.Kind == "pricing" {
+		price, err := pricing.Deserialize(dir, []string{e.Name})
+		if err != nil {
+			e.Error = err.Error()
+			return e
+		}
+
+		version := make(map[string]bool)
+		for _, p := range price {
+			version[p.Version] = true
+		}
+
+		for k := range version {
+			e.Version = append(e.Version, k)
+		}
+		sort.Strings(e.Version)
+
+		e.Rows = len(price)
+		e.Verified = true
+		return e
+	}
+
+	if e.Kind == "usage" {
+		if _, err := time.Parse("2006-01", e.Name); err != nil {
+			e.Error = fmt.Sprintf("invalid month: %s", e.Name)
+			return e
+		}
+
+		quantity, err := usage.Deserialize(dir, []usage.Date{{Start: fmt.Sprintf("%s-01", e.Name)}})
+		if err != nil {
+			e.Error = err.Error()
+			return e
+		}
+
+		e.Rows = len(quantity)
+		e.Verified = true
+		return e
+	}
+
+	if e.Kind == "dimension" {
+		date, err := usage.NewDate(e.Name)
+		if err != nil {
+			e.Error = fmt.Sprintf("invalid month: %s", e.Name)
+			return e
+		}
+
+		d, _, err := usage.DeserializeDimension(dir, date)
+		if err != nil {
+			e.Error = err.Error()
+			return e
+		}
+
+		e.Rows = len(d.Account) + len(d.UsageType)
+		e.Verified = true
+		return e
+	}
+
+	e.Error = fmt.Sprintf("unknown kind: %s", e.Kind)
+	return e
+}
@@ -0,0 +1,148 @@
+package denormalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/hermes"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/itsubaki/hermes/pkg/usage"
+	"github.com/urfave/cli"
+)
+
+type Purchase struct {
+	Price    pricing.Price    `json:"price"`
+	Quantity []usage.Quantity `json:"quantity"`
+}
+
+type Output struct {
+	Quantity  usage.Quantity   `json:"quantity"`
+	Purchase  []usage.Quantity `json:"purchase"`
+	Remainder float64          `json:"remainder,omitempty"`
+}
+
+// Action converts the normalized quantities of the break-even point of each purchase in stdin
+// to the sizes of its family in the cached pricing.
+func Action(c *cli.Context) {
+	strategy := c.String("strategy")
+	if strategy != hermes.LargestFirst && strategy != hermes.CurrentMix {
+		fmt.Printf("unknown strategy: %s\n", strategy)
+		os.Exit(1)
+	}
+
+	rules := hermes.DefaultRules
+	if len(c.String("rules")) > 0 {
+		r, err := hermes.ReadRules(c.String("rules"))
+		if err != nil {
+			fmt.Printf("read rules: %v\n", err)
+			os.Exit(1)
+		}
+
+		rules = r
+	}
+
+	mapping := hermes.Mapping{}
+	if len(c.GlobalString("mapping")) > 0 {
+		m, err := hermes.ReadMapping(c.GlobalString("mapping"))
+		if err != nil {
+			fmt.Printf("read mapping: %v\n", err)
+			os.Exit(1)
+		}
+
+		mapping = m
+	}
+
+	date, err := hermes.ParseDate(c.String("date"))
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	stdin, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Printf("read stdin: %v\n", err)
+		os.Exit(1)
+	}
+
+	var purchase []Purchase
+	if err := json.Unmarshal(stdin, &purchase); err != nil {
+		fmt.Printf("unmarshal: %v\n", err)
+		os.Exit(1)
+	}
+
+	s, err := storage.New(c.GlobalString("storage"), c.GlobalString("dir"), cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	var current []usage.Quantity
+	if strategy == hermes.CurrentMix {
+		current, err = s.ReadUsage(usage.Last12Months())
+		if err != nil {
+			fmt.Printf("read usage: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	plist := make(map[string][]pricing.Price)
+	for _, p := range purchase {
+		if _, ok := plist[p.Price.Region]; ok {
+			continue
+		}
+
+		price, err := s.ReadPricing([]string{p.Price.Region})
+		if err != nil {
+			fmt.Printf("read pricing: %v\n", err)
+			os.Exit(1)
+		}
+
+		plist[p.Price.Region], _ = pricing.Dedupe(price)
+	}
+
+	for _, p := range purchase {
+		q, _ := hermes.BreakEvenPoint(p.Quantity, p.Price)
+
+		out, remainder, err := hermes.Denormalize(&hermes.DenormalizeInput{
+			Quantity: q,
+			Size:     hermes.Sizes(plist[p.Price.Region], p.Price, rules, date),
+			Strategy: strategy,
+			Current:  same(current, p.Price, mapping),
+			MaxCount: c.Int("max-count"),
+		})
+		if err != nil {
+			fmt.Printf("denormalize %s: %v\n", q.UsageType, err)
+			os.Exit(1)
+		}
+
+		bytes, err := json.Marshal(Output{Quantity: q, Purchase: out, Remainder: remainder})
+		if err != nil {
+			fmt.Printf("marshal: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(bytes))
+	}
+}
+
+// same returns the quantities of the region, platform and engine of p by m in any size.
+func same(quantity []usage.Quantity, p pricing.Price, m hermes.Mapping) []usage.Quantity {
+	out := make([]usage.Quantity, 0)
+	for _, q := range quantity {
+		if !m.MatchPlatform(q, p) {
+			continue
+		}
+
+		out = append(out, q)
+	}
+
+	return out
+}
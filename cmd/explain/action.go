@@ -0,0 +1,125 @@
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/hermes"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/itsubaki/hermes/pkg/usage"
+	"github.com/urfave/cli"
+)
+
+// Action outputs how the usage of --usage-type and --platform (or engine) is matched to the cached pricing and normalized.
+func Action(c *cli.Context) {
+	format := c.String("format")
+	if len(c.String("usage-type")) < 1 {
+		fmt.Println("usage type is required")
+		os.Exit(1)
+	}
+
+	s, err := storage.New(c.GlobalString("storage"), c.GlobalString("dir"), cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	plist, err := s.ReadPricing([]string{c.String("region")})
+	if err != nil {
+		fmt.Printf("read pricing: %v\n", err)
+		os.Exit(1)
+	}
+
+	rules := hermes.DefaultRules
+	if len(c.String("rules")) > 0 {
+		rules, err = hermes.ReadRules(c.String("rules"))
+		if err != nil {
+			fmt.Printf("read rules: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	mapping := hermes.Mapping{}
+	if len(c.GlobalString("mapping")) > 0 {
+		mapping, err = hermes.ReadMapping(c.GlobalString("mapping"))
+		if err != nil {
+			fmt.Printf("read mapping: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	purchase, err := hermes.ParseDate(c.String("date"))
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	e, err := hermes.Explain(&hermes.ExplainInput{
+		Quantity: usage.Quantity{
+			Region:         c.String("region"),
+			UsageType:      c.String("usage-type"),
+			Platform:       c.String("platform"),
+			CacheEngine:    c.String("cache-engine"),
+			DatabaseEngine: c.String("database-engine"),
+			InstanceNum:    1,
+		},
+		Price:   plist,
+		Rules:   rules,
+		Date:    purchase,
+		Mapping: mapping,
+	})
+	if ierr, ok := err.(*pricing.InvalidError); ok {
+		for _, v := range ierr.Invalid {
+			fmt.Fprintf(os.Stderr, "skip: %v\n", v)
+		}
+	}
+
+	if format == "json" {
+		bytes, err := json.Marshal(e)
+		if err != nil {
+			fmt.Printf("marshal: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(string(bytes))
+		return
+	}
+
+	minimum, factor := "", ""
+	if e.Minimum != nil {
+		minimum, factor = e.Minimum.UsageType, e.Minimum.NormalizationSizeFactor
+	}
+
+	fmt.Println("status, reason, sku, family, minimum, minimum_normalization_factor, scale, offer_term_code, lease_contract_length, purchase_option, offering_class, on_demand, reserved_quantity, reserved_hours")
+	if len(e.Offering) < 1 {
+		fmt.Printf("%s, %s, %s, %s, %s, %s, %.3f, , , , , , , \n", e.Status, e.Reason, e.SKU, e.Family, minimum, factor, e.Scale)
+		return
+	}
+
+	for _, p := range e.Offering {
+		fmt.Printf(
+			"%s, %s, %s, %s, %s, %s, %.3f, %s, %s, %s, %s, %.3f, %.3f, %.3f\n",
+			e.Status,
+			e.Reason,
+			p.SKU,
+			e.Family,
+			minimum,
+			factor,
+			e.Scale,
+			p.OfferTermCode,
+			p.LeaseContractLength,
+			p.PurchaseOption,
+			p.OfferingClass,
+			p.OnDemand,
+			p.ReservedQuantity,
+			p.ReservedHrs,
+		)
+	}
+}
@@ -1,12 +1,25 @@
 package fetch
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/itsubaki/hermes/cmd/fetch/pricing"
 	"github.com/itsubaki/hermes/cmd/fetch/usage"
 	"github.com/urfave/cli"
 )
 
+// Action fetches pricing, then usage. A pricing failure is reported and usage is still fetched,
+// and the exit status is 1.
 func Action(c *cli.Context) {
-	pricing.Action(c)
+	err := pricing.Fetch(c)
+	if err != nil {
+		fmt.Printf("fetch pricing: %v\n", err)
+	}
+
 	usage.Action(c)
+
+	if err != nil {
+		os.Exit(1)
+	}
 }
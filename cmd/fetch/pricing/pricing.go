@@ -2,48 +2,111 @@ package pricing
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 
+	"github.com/itsubaki/hermes/pkg/cache"
 	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
 	"github.com/urfave/cli"
 )
 
 func Action(c *cli.Context) {
+	if err := Fetch(c); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// Fetch fetches the pricing of the regions in --region missing in the cache.
+// It returns an error when a region failed, after fetching the others.
+func Fetch(c *cli.Context) error {
 	region := c.StringSlice("region")
 	dir := c.GlobalString("dir")
 
-	path := fmt.Sprintf("%s/pricing", dir)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		os.MkdirAll(path, os.ModePerm)
+	endpoint := c.String("endpoint")
+	if len(endpoint) < 1 {
+		endpoint = pricing.BaseURL
 	}
+	url := pricing.URLList(endpoint)
 
 	for _, r := range region {
-		file := fmt.Sprintf("%s/%s.out", path, r)
-		if _, err := os.Stat(file); !os.IsNotExist(err) {
+		if r != pricing.AllRegion {
 			continue
 		}
 
-		price := make([]pricing.Price, 0)
-		for _, url := range pricing.URL {
-			p, err := pricing.Fetch(url, r)
-			if err != nil {
-				fmt.Printf("fetch pricing (%s, %s): %v\n", url, r, err)
-				os.Exit(1)
-			}
+		all, err := pricing.FetchRegionList(url, http.DefaultClient)
+		if err != nil {
+			return fmt.Errorf("fetch region list: %v", err)
+		}
+
+		region = all
+		break
+	}
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		return fmt.Errorf("lock: %v", err)
+	}
+	defer l.Unlock()
+
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Source:        endpoint,
+		Compress:      c.GlobalBool("gzip"),
+		Overwrite:     c.Bool("update"),
+	})
+	if err != nil {
+		return fmt.Errorf("new storage: %v", err)
+	}
+	defer s.Close()
 
-			list := make([]pricing.Price, 0)
-			for k := range p {
-				list = append(list, p[k])
+	missing := make([]string, 0)
+	for _, r := range region {
+		ok, err := s.ExistsPricing(r)
+		if err != nil {
+			return fmt.Errorf("exists pricing (%s): %v", r, err)
+		}
+
+		if ok && !c.Bool("update") {
+			continue
+		}
+
+		missing = append(missing, r)
+	}
+
+	failed := make([]string, 0)
+	pricing.FetchAll(pricing.FetchAllInput{
+		URL:      url,
+		Region:   missing,
+		Parallel: c.Int("parallel"),
+		Progress: func(done, total int, url, region string, err error) {
+			if err != nil {
+				fmt.Printf("[%d/%d] fetch: %s %s: %v\n", done, total, pricing.Service(url), region, err)
+				return
 			}
 
-			price = append(price, list...)
+			fmt.Printf("[%d/%d] fetch: %s %s\n", done, total, pricing.Service(url), region)
+		},
+	}, func(r pricing.Result) {
+		if r.Err != nil {
+			fmt.Printf("fetch pricing (%s): %v\n", r.Region, r.Err)
+			failed = append(failed, r.Region)
+			return
 		}
 
-		if err := pricing.Serialize(dir, r, price); err != nil {
-			fmt.Printf("serialize: %v\n", err)
-			os.Exit(1)
+		if err := s.WritePricing(r.Region, r.Price); err != nil {
+			fmt.Printf("write pricing (%s): %v\n", r.Region, err)
+			failed = append(failed, r.Region)
+			return
 		}
 
-		fmt.Printf("write: %v\n", file)
+		fmt.Printf("write: pricing/%s\n", r.Region)
+	})
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed: %v", failed)
 	}
+
+	return nil
 }
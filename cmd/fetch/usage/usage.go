@@ -1,46 +1,144 @@
 package usage
 
 import (
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/storage"
 	"github.com/itsubaki/hermes/pkg/usage"
 	"github.com/urfave/cli"
 )
 
 func Action(c *cli.Context) {
 	dir := c.GlobalString("dir")
-	path := fmt.Sprintf("%s/usage", dir)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		os.MkdirAll(path, os.ModePerm)
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	budget := usage.NewBudget(c.Int("max-ce-requests"))
+	client := &usage.Client{
+		CostExplorer: usage.NewCostExplorer(),
+		Limiter:      usage.NewLimiter(c.Float64("rate-limit"), 1),
+		Budget:       budget,
+		MaxRetries:   c.Int("max-retries"),
+	}
+
+	missing := make([]usage.Date, 0)
+	for _, d := range usage.Last12Months() {
+		ok, err := s.ExistsUsage(d)
+		if err != nil {
+			fmt.Printf("exists usage (%s): %v\n", d.YYYYMM(), err)
+			os.Exit(1)
+		}
+
+		if ok {
+			continue
+		}
+
+		missing = append(missing, d)
+	}
+
+	if len(missing) < 1 {
+		return
 	}
 
-	date := usage.Last12Months()
-	for i := range date {
-		file := fmt.Sprintf("%s/%s.out", path, date[i].YYYYMM())
-		if _, err := os.Stat(file); !os.IsNotExist(err) {
+	failed := make([]string, 0)
+	for _, d := range missing {
+		if budget.Exceeded() {
+			fmt.Printf("skip: usage/%s: %v\n", d.YYYYMM(), usage.ErrBudgetExceeded)
+			failed = append(failed, d.YYYYMM())
 			continue
 		}
 
-		u, err := usage.Fetch(date[i].Start, date[i].End)
+		// dimension values of a past month do not change. they are cached and reused.
+		dimension, ok, err := usage.DeserializeDimension(dir, d)
 		if err != nil {
-			fmt.Printf("fetch usage (%s, %s): %v\n", date[i].Start, date[i].End, err)
-			os.Exit(1)
+			fmt.Printf("read dimension (%s): %v\n", d.YYYYMM(), err)
+		}
+
+		if !ok || err != nil {
+			dimension, err = usage.FetchDimension(client, d)
+			if err != nil {
+				fmt.Printf("fetch dimension (%s): %v\n", d.YYYYMM(), err)
+				summary(budget)
+				os.Exit(1)
+			}
+
+			if err := usage.SerializeDimension(dir, d, dimension, cache.Option{
+				HermesVersion: c.App.Version,
+				Compress:      c.GlobalBool("gzip"),
+			}); err != nil {
+				fmt.Printf("write dimension (%s): %v\n", d.YYYYMM(), err)
+				summary(budget)
+				os.Exit(1)
+			}
+		}
+
+		fetch := usage.FetchWithInput
+		if c.Bool("consolidated") {
+			fetch = usage.FetchConsolidated
+		}
+
+		u, err := fetch(&usage.FetchInput{
+			Start:     d.Start,
+			End:       d.End,
+			Client:    client,
+			Parallel:  c.Int("parallel"),
+			Account:   dimension.Account,
+			UsageType: dimension.UsageType,
+		})
+		if perr, ok := err.(*usage.PartialError); ok {
+			for _, f := range perr.Failed {
+				fmt.Printf("fetch usage (%s): failed: %v\n", d.YYYYMM(), f)
+			}
+
+			fmt.Printf("skip: usage/%s: %d requests failed\n", d.YYYYMM(), len(perr.Failed))
+			failed = append(failed, d.YYYYMM())
+			continue
 		}
 
-		bytes, err := json.Marshal(u)
 		if err != nil {
-			fmt.Printf("marshal: %v\n", err)
+			fmt.Printf("fetch usage (%s, %s): %v\n", d.Start, d.End, err)
+			summary(budget)
 			os.Exit(1)
 		}
 
-		if err := ioutil.WriteFile(file, bytes, os.ModePerm); err != nil {
-			fmt.Errorf("write file: %v\n", err)
+		if err := s.WriteUsage(d, u); err != nil {
+			fmt.Printf("write usage (%s): %v\n", d.YYYYMM(), err)
+			summary(budget)
 			os.Exit(1)
 		}
 
-		fmt.Printf("write: %v\n", file)
+		fmt.Printf("write: usage/%s\n", d.YYYYMM())
+	}
+
+	summary(budget)
+	if len(failed) > 0 {
+		fmt.Printf("failed: %v\n", failed)
+		os.Exit(1)
 	}
 }
+
+func summary(b *usage.Budget) {
+	api, count := b.Count()
+	for i := range api {
+		fmt.Printf("cost explorer: %s: %d requests\n", api[i], count[i])
+	}
+
+	fmt.Printf("cost explorer: %d requests, estimated $%.2f\n", b.Total(), b.Cost())
+}
@@ -0,0 +1,92 @@
+package imports
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/itsubaki/hermes/pkg/usage"
+	"github.com/urfave/cli"
+)
+
+// CUR imports the usage of Cost and Usage Report files.
+// The usage of the accounts in the files replaces theirs in the cached months. The other accounts are kept.
+func CUR(c *cli.Context) {
+	dir := c.GlobalString("dir")
+
+	path := c.Args().First()
+	if len(path) < 1 {
+		fmt.Println("usage: hermes import cur <path>")
+		os.Exit(1)
+	}
+
+	quantity, err := usage.ReadCUR(path)
+	if err != nil {
+		fmt.Printf("read cur: %v\n", err)
+		os.Exit(1)
+	}
+
+	monthly := make(map[string][]usage.Quantity)
+	for _, q := range quantity {
+		monthly[q.Date] = append(monthly[q.Date], q)
+	}
+
+	month := make([]string, 0)
+	for m := range monthly {
+		month = append(month, m)
+	}
+	sort.Strings(month)
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Source:        "cur",
+		Compress:      c.GlobalBool("gzip"),
+		Overwrite:     true,
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	for _, m := range month {
+		d, err := usage.NewDate(m)
+		if err != nil {
+			fmt.Printf("new date: %v\n", err)
+			os.Exit(1)
+		}
+
+		quantity := monthly[m]
+		ok, err := s.ExistsUsage(d)
+		if err != nil {
+			fmt.Printf("exists usage (%s): %v\n", m, err)
+			os.Exit(1)
+		}
+
+		if ok {
+			cached, err := s.ReadUsage([]usage.Date{d})
+			if err != nil {
+				fmt.Printf("read usage (%s): %v\n", m, err)
+				os.Exit(1)
+			}
+
+			quantity = usage.ReplaceAccount(cached, quantity)
+		}
+
+		if err := s.WriteUsage(d, quantity); err != nil {
+			fmt.Printf("write usage (%s): %v\n", m, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("write: usage/%s (%d)\n", m, len(quantity))
+	}
+}
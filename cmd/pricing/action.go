@@ -5,7 +5,8 @@ import (
 	"fmt"
 	"os"
 
-	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/storage"
 	"github.com/urfave/cli"
 )
 
@@ -14,9 +15,25 @@ func Action(c *cli.Context) {
 	dir := c.GlobalString("dir")
 	format := c.String("format")
 
-	price, err := pricing.Deserialize(dir, region)
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
 	if err != nil {
-		fmt.Printf("deserialize: %v\n", err)
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	region, err = storage.Region(s, region)
+	if err != nil {
+		fmt.Printf("list pricing: %v\n", err)
+		os.Exit(1)
+	}
+
+	price, err := s.ReadPricing(region)
+	if err != nil {
+		fmt.Printf("read pricing: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -34,18 +51,19 @@ func Action(c *cli.Context) {
 	}
 
 	if format == "csv" {
-		fmt.Println("id, discount_rate, break_even_point(month), version, region, instance_type, usage_type, lease_contract_length, purchase_option, os/engine, tenancy, pre_installed, operation, offering_class, on_demand, reserved_quantity, reserved_hours, normalization_factor")
+		fmt.Println("id, discount_rate, break_even_point(month), version, region, instance_type, usage_type, lease_contract_length, purchase_option, os/engine, tenancy, pre_installed, operation, offering_class, on_demand, reserved_quantity, reserved_hours, normalization_factor, vcpu, memory, instance_family, physical_processor, network_performance, current_generation, deployment_option, license_model, capacity_status, location, database_edition")
 		for _, p := range price {
 			fmt.Printf(
-				"%s, %.2f, %d, %s, %s, %s, %s, %s, %s, %s%s%s, %s, %s, %s, %s, %.3f, %.3f, %.3f, %s\n",
+				"%s, %.2f, %d, %s, %s, %s, %s, %s, %s, %s%s%s, %s, %s, %s, %s, %.3f, %.3f, %.3f, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s\n",
 				fmt.Sprintf(
-					"%s_%s_%s_%s%s%s_%s_%s_%s",
+					"%s_%s_%s_%s%s%s%s_%s_%s_%s",
 					p.UsageType,
 					p.LeaseContractLength,
 					p.PurchaseOption,
 					p.OperatingSystem,
 					p.CacheEngine,
 					p.DatabaseEngine,
+					p.DatabaseEdition,
 					p.Tenancy,
 					p.PreInstalled,
 					p.OfferingClass,
@@ -69,6 +87,17 @@ func Action(c *cli.Context) {
 				p.ReservedQuantity,
 				p.ReservedHrs,
 				p.NormalizationSizeFactor,
+				p.VCPU,
+				p.Memory,
+				p.InstanceFamily,
+				p.PhysicalProcessor,
+				p.NetworkPerformance,
+				p.CurrentGeneration,
+				p.DeploymentOption,
+				p.LicenseModel,
+				p.CapacityStatus,
+				p.Location,
+				p.DatabaseEdition,
 			)
 		}
 		return
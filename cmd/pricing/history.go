@@ -0,0 +1,195 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/urfave/cli"
+)
+
+// History outputs the snapshots of each region and their price list versions.
+func History(c *cli.Context) {
+	s, region := open(c)
+	defer s.Close()
+
+	for _, r := range region {
+		snapshot, err := s.ListPricingSnapshot(r)
+		if err != nil {
+			fmt.Printf("list pricing snapshot (%s): %v\n", r, err)
+			os.Exit(1)
+		}
+
+		for _, ss := range snapshot {
+			price, err := s.ReadPricingSnapshot(r, ss)
+			if err != nil {
+				fmt.Printf("read pricing snapshot (%s/%s): %v\n", r, ss, err)
+				os.Exit(1)
+			}
+
+			fmt.Printf("%s, %s, %d, %s\n", r, ss, len(price), strings.Join(pricing.Version(price), " "))
+		}
+	}
+}
+
+// Diff outputs the changes of prices between two snapshots of each region.
+// The latest two snapshots are compared by default.
+// --from and --to are a snapshot or a price list version.
+func Diff(c *cli.Context) {
+	format := c.String("format")
+
+	s, region := open(c)
+	defer s.Close()
+
+	if format == "csv" {
+		fmt.Println("kind, region, sku, offer_term_code, usage_type, os/engine, lease_contract_length, purchase_option, offering_class, on_demand(from), on_demand(to), reserved_quantity(from), reserved_quantity(to), reserved_hours(from), reserved_hours(to)")
+	}
+
+	for _, r := range region {
+		snapshot, err := s.ListPricingSnapshot(r)
+		if err != nil {
+			fmt.Printf("list pricing snapshot (%s): %v\n", r, err)
+			os.Exit(1)
+		}
+
+		from, err := resolve(s, r, snapshot, c.String("from"), false)
+		if err != nil {
+			fmt.Printf("from (%s): %v\n", r, err)
+			os.Exit(1)
+		}
+
+		to, err := resolve(s, r, snapshot, c.String("to"), true)
+		if err != nil {
+			fmt.Printf("to (%s): %v\n", r, err)
+			os.Exit(1)
+		}
+
+		if len(to) < 1 && len(snapshot) > 0 {
+			to = snapshot[len(snapshot)-1]
+		}
+
+		if len(from) < 1 {
+			for i := range snapshot {
+				if snapshot[i] < to {
+					from = snapshot[i]
+				}
+			}
+		}
+
+		if len(from) < 1 || len(to) < 1 {
+			fmt.Printf("diff (%s): need two snapshots, found %v\n", r, snapshot)
+			os.Exit(1)
+		}
+
+		fp, err := s.ReadPricingSnapshot(r, from)
+		if err != nil {
+			fmt.Printf("read pricing snapshot (%s/%s): %v\n", r, from, err)
+			os.Exit(1)
+		}
+
+		tp, err := s.ReadPricingSnapshot(r, to)
+		if err != nil {
+			fmt.Printf("read pricing snapshot (%s/%s): %v\n", r, to, err)
+			os.Exit(1)
+		}
+
+		for _, d := range pricing.Diff(fp, tp) {
+			if format == "json" {
+				bytes, err := json.Marshal(d)
+				if err != nil {
+					fmt.Printf("marshal: %v\n", err)
+					os.Exit(1)
+				}
+
+				fmt.Println(string(bytes))
+				continue
+			}
+
+			p := d.Price()
+			fmt.Printf(
+				"%s, %s, %s, %s, %s, %s%s%s, %s, %s, %s, %.3f, %.3f, %.3f, %.3f, %.3f, %.3f\n",
+				d.Kind,
+				r,
+				p.SKU,
+				p.OfferTermCode,
+				p.UsageType,
+				p.OperatingSystem,
+				p.CacheEngine,
+				p.DatabaseEngine,
+				p.LeaseContractLength,
+				p.PurchaseOption,
+				p.OfferingClass,
+				d.From.OnDemand,
+				d.To.OnDemand,
+				d.From.ReservedQuantity,
+				d.To.ReservedQuantity,
+				d.From.ReservedHrs,
+				d.To.ReservedHrs,
+			)
+		}
+	}
+}
+
+// resolve returns the snapshot of region v stands for. v is a snapshot or a price list version.
+// A version stands for the latest snapshot including it, or the earliest one when earliest is true,
+// so that the snapshots between from and to differ in as few versions as possible.
+func resolve(s storage.Storage, region string, snapshot []string, v string, earliest bool) (string, error) {
+	if len(v) < 1 {
+		return "", nil
+	}
+
+	for _, ss := range snapshot {
+		if ss == v {
+			return v, nil
+		}
+	}
+
+	found := ""
+	for _, ss := range snapshot {
+		version, err := s.ReadPricingSnapshotVersion(region, ss)
+		if err != nil {
+			return "", fmt.Errorf("read pricing snapshot version (%s): %v", ss, err)
+		}
+
+		for _, vv := range version {
+			if vv != v {
+				continue
+			}
+
+			if earliest {
+				return ss, nil
+			}
+
+			found = ss
+		}
+	}
+
+	if len(found) < 1 {
+		return "", fmt.Errorf("snapshot or version not found: %s", v)
+	}
+
+	return found, nil
+}
+
+func open(c *cli.Context) (storage.Storage, []string) {
+	s, err := storage.New(c.GlobalString("storage"), c.GlobalString("dir"), cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+
+	region, err := storage.Region(s, c.StringSlice("region"))
+	if err != nil {
+		fmt.Printf("list pricing: %v\n", err)
+		os.Exit(1)
+	}
+
+	return s, region
+}
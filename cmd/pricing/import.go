@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/urfave/cli"
+)
+
+// Import replaces the prices of service in the pricing of region with the prices of a downloaded offer file.
+func Import(c *cli.Context) {
+	dir := c.GlobalString("dir")
+	service := c.String("service")
+	region := c.String("region")
+	file := c.String("file")
+
+	if len(service) < 1 || len(region) < 1 || len(file) < 1 {
+		fmt.Println("usage: hermes pricing import --service AmazonEC2 --region ap-northeast-1 --file offer.json")
+		os.Exit(1)
+	}
+
+	imported, err := pricing.Import(file, service, region)
+	if err != nil {
+		fmt.Printf("import %s: %v\n", file, err)
+		os.Exit(1)
+	}
+
+	if len(imported) < 1 {
+		fmt.Printf("import %s: no prices of %s in %s\n", file, service, region)
+		os.Exit(1)
+	}
+
+	l, err := cache.Lock(dir, func(file string) { fmt.Printf("waiting for lock: %s\n", file) })
+	if err != nil {
+		fmt.Printf("lock: %v\n", err)
+		os.Exit(1)
+	}
+	defer l.Unlock()
+
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Source:        file,
+		Compress:      c.GlobalBool("gzip"),
+		Overwrite:     true,
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	ok, err := s.ExistsPricing(region)
+	if err != nil {
+		fmt.Printf("exists pricing (%s): %v\n", region, err)
+		os.Exit(1)
+	}
+
+	price := make([]pricing.Price, 0)
+	if ok {
+		p, err := s.ReadPricing([]string{region})
+		if err != nil {
+			fmt.Printf("read pricing (%s): %v\n", region, err)
+			os.Exit(1)
+		}
+
+		price = p
+	}
+
+	if err := s.WritePricing(region, pricing.Merge(price, imported, service)); err != nil {
+		fmt.Printf("write pricing (%s): %v\n", region, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("write: pricing/%s (%s: %d)\n", region, service, len(imported))
+}
@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/urfave/cli"
+)
+
+// Validate outputs the prices sharing a key with the canonical price and the rule dropping them.
+// Duplicates dropped by the sku rule are ambiguous and exit with status 1 when --strict is set.
+func Validate(c *cli.Context) {
+	format := c.String("format")
+
+	s, region := open(c)
+	defer s.Close()
+
+	price, err := s.ReadPricing(region)
+	if err != nil {
+		fmt.Printf("read pricing: %v\n", err)
+		os.Exit(1)
+	}
+
+	_, dup := pricing.Dedupe(price)
+
+	if format == "csv" {
+		fmt.Println("rule, region, usage_type, os/engine, pre_installed, tenancy, lease_contract_length, purchase_option, offering_class, selected(sku), selected(capacity_status), selected(license_model), selected(version), dropped(sku), dropped(capacity_status), dropped(license_model), dropped(version)")
+	}
+
+	ambiguous := 0
+	for _, d := range dup {
+		if d.Rule == pricing.SKURule {
+			ambiguous++
+		}
+
+		if format == "json" {
+			bytes, err := json.Marshal(d)
+			if err != nil {
+				fmt.Printf("marshal: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+			continue
+		}
+
+		p := d.Selected
+		fmt.Printf(
+			"%s, %s, %s, %s%s%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s\n",
+			d.Rule,
+			p.Region,
+			p.UsageType,
+			p.OperatingSystem,
+			p.CacheEngine,
+			p.DatabaseEngine,
+			p.PreInstalled,
+			p.Tenancy,
+			p.LeaseContractLength,
+			p.PurchaseOption,
+			p.OfferingClass,
+			p.SKU,
+			p.CapacityStatus,
+			p.LicenseModel,
+			p.Version,
+			d.Dropped.SKU,
+			d.Dropped.CapacityStatus,
+			d.Dropped.LicenseModel,
+			d.Dropped.Version,
+		)
+	}
+
+	if ambiguous > 0 && c.Bool("strict") {
+		fmt.Printf("ambiguous prices: %d\n", ambiguous)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/itsubaki/hermes/pkg/storage"
+	"github.com/urfave/cli"
+)
+
+func Action(c *cli.Context) {
+	dir := c.GlobalString("dir")
+	format := c.String("format")
+
+	query := strings.Join(c.Args(), " ")
+	if len(query) < 1 {
+		fmt.Println("usage: hermes query \"SELECT ...\"")
+		os.Exit(1)
+	}
+
+	file := storage.SQLitePath(dir)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		fmt.Printf("file not found: %s. run `hermes --storage sqlite fetch` first\n", file)
+		os.Exit(1)
+	}
+
+	s, err := storage.NewSQLiteReadOnly(file)
+	if err != nil {
+		fmt.Printf("new sqlite: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	columns, rows, err := s.Query(query)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+
+	if format == "json" {
+		for _, r := range rows {
+			m := make(map[string]interface{})
+			for i := range columns {
+				m[columns[i]] = r[i]
+			}
+
+			bytes, err := json.Marshal(m)
+			if err != nil {
+				fmt.Printf("marshal: %v\n", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+		}
+		return
+	}
+
+	if format == "csv" {
+		fmt.Println(strings.Join(columns, ", "))
+		for _, r := range rows {
+			val := make([]string, 0)
+			for i := range r {
+				val = append(val, fmt.Sprintf("%v", r[i]))
+			}
+
+			fmt.Println(strings.Join(val, ", "))
+		}
+		return
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/itsubaki/hermes/pkg/cache"
 	"github.com/itsubaki/hermes/pkg/hermes"
 	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/storage"
 	"github.com/itsubaki/hermes/pkg/usage"
 	"github.com/urfave/cli"
 )
@@ -16,27 +18,92 @@ func Action(c *cli.Context) {
 	dir := c.GlobalString("dir")
 	format := c.String("format")
 	normalize := c.Bool("normalize")
+	lenient := c.Bool("lenient")
 	merge := c.Bool("merge")
 	overall := c.Bool("merge-overall")
 	monthly := c.Bool("monthly")
+	units := c.Bool("units")
+	unmatched := c.Bool("unmatched")
+
+	s, err := storage.New(c.GlobalString("storage"), dir, cache.Option{
+		HermesVersion: c.App.Version,
+		Compress:      c.GlobalBool("gzip"),
+	})
+	if err != nil {
+		fmt.Printf("new storage: %v\n", err)
+		os.Exit(1)
+	}
+	defer s.Close()
+
+	mapping := hermes.Mapping{}
+	if len(c.GlobalString("mapping")) > 0 {
+		mapping, err = hermes.ReadMapping(c.GlobalString("mapping"))
+		if err != nil {
+			fmt.Printf("read mapping: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	region, err = storage.Region(s, region)
+	if err != nil {
+		fmt.Printf("list pricing: %v\n", err)
+		os.Exit(1)
+	}
 
 	date := usage.Last12Months()
-	quantity, err := usage.Deserialize(dir, date)
+	quantity, err := s.ReadUsage(date)
 	if err != nil {
-		fmt.Errorf("deserialize usage: %v\n", err)
+		fmt.Printf("read usage: %v\n", err)
 		os.Exit(1)
 	}
 
-	if normalize {
-		plist, err := pricing.Deserialize(dir, region)
+	if unmatched {
+		plist, err := s.ReadPricing(region)
 		if err != nil {
-			fmt.Errorf("desirialize pricing: %v\n", err)
+			fmt.Printf("read pricing: %v\n", err)
+			os.Exit(1)
 		}
 
-		family := pricing.Family(plist)
-		mini := pricing.Minimum(family, plist)
+		coverage(hermes.Cover(inRegion(quantity, region), plist, mapping), format)
+		return
+	}
 
-		quantity = hermes.Normalize(quantity, mini)
+	if normalize && !units {
+		mini := minimum(s, region, lenient)
+
+		rules := hermes.DefaultRules
+		if len(c.String("rules")) > 0 {
+			rules, err = hermes.ReadRules(c.String("rules"))
+			if err != nil {
+				fmt.Printf("read rules: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		purchase, err := hermes.ParseDate(c.String("date"))
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+
+		result, err := hermes.NormalizeWithInput(&hermes.NormalizeInput{
+			Quantity: quantity,
+			Minimum:  mini,
+			Rules:    rules,
+			Date:     purchase,
+			Mapping:  mapping,
+		})
+		invalid("normalize", err, lenient)
+
+		if c.Bool("report") {
+			report(result, format)
+			return
+		}
+
+		quantity = make([]usage.Quantity, 0)
+		for _, r := range result {
+			quantity = append(quantity, r.Output)
+		}
 	}
 
 	if merge {
@@ -47,6 +114,23 @@ func Action(c *cli.Context) {
 		quantity = usage.MergeOverall(quantity)
 	}
 
+	if units {
+		u, skipped, err := hermes.NormalizedUnits(quantity, minimum(s, region, lenient), mapping)
+		invalid("units", err, lenient)
+
+		if c.Bool("report") {
+			report(skipped, format)
+			return
+		}
+
+		if len(skipped) > 0 {
+			fmt.Fprintf(os.Stderr, "units: skip %d usage without a price or normalization size factor. see --report\n", len(skipped))
+		}
+
+		output(u, format)
+		return
+	}
+
 	if format == "json" && !monthly {
 		usage.Sort(quantity)
 		for _, q := range quantity {
@@ -107,3 +191,179 @@ func Action(c *cli.Context) {
 		}
 	}
 }
+
+// minimum returns the minimum prices of the cached pricing of region.
+func minimum(s storage.Storage, region []string, lenient bool) map[string]pricing.Tuple {
+	plist, err := s.ReadPricing(region)
+	if err != nil {
+		fmt.Printf("read pricing: %v\n", err)
+		os.Exit(1)
+	}
+
+	family, err := pricing.Family(plist)
+	invalid("family", err, lenient)
+
+	mini, err := pricing.Minimum(family, plist)
+	invalid("minimum", err, lenient)
+
+	return mini
+}
+
+// output outputs the normalized units of each family and month, and their totals with account_id "total".
+func output(units []hermes.Units, format string) {
+	if format == "csv" {
+		fmt.Println("account_id, description, region, family, os/engine, date, instance_hour, normalized_units, size_mix")
+	}
+
+	for _, u := range units {
+		if format == "json" {
+			bytes, err := json.Marshal(u)
+			if err != nil {
+				fmt.Printf("marshal: %v", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+			continue
+		}
+
+		account := u.AccountID
+		if u.Total {
+			account = "total"
+		}
+
+		fmt.Printf(
+			"%s, %s, %s, %s, %s%s%s, %s, %.3f, %.3f, %s\n",
+			account,
+			u.Description,
+			u.Region,
+			u.Family,
+			u.Platform,
+			u.CacheEngine,
+			u.DatabaseEngine,
+			u.Date,
+			u.InstanceHour,
+			u.NormalizedUnits,
+			u.SizeMixString(),
+		)
+	}
+}
+
+// invalid exits when err is not nil.
+// In lenient mode, the invalid prices are reported and skipped.
+func invalid(name string, err error, lenient bool) {
+	if err == nil {
+		return
+	}
+
+	if ierr, ok := err.(*pricing.InvalidError); ok && lenient {
+		for _, e := range ierr.Invalid {
+			fmt.Fprintf(os.Stderr, "%s: skip: %v\n", name, e)
+		}
+
+		return
+	}
+
+	fmt.Printf("%s: %v\n", name, err)
+	os.Exit(1)
+}
+
+// report outputs how each quantity was normalized.
+func report(result []hermes.Result, format string) {
+	if format == "csv" {
+		fmt.Println("status, reason, account_id, date, region, usage_type, os/engine, instance_num, usage_type(normalized), instance_num(normalized)")
+	}
+
+	for _, r := range result {
+		if format == "json" {
+			bytes, err := json.Marshal(r)
+			if err != nil {
+				fmt.Printf("marshal: %v", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+			continue
+		}
+
+		q := r.Quantity
+		fmt.Printf(
+			"%s, %s, %s, %s, %s, %s, %s%s%s, %.3f, %s, %.3f\n",
+			r.Status,
+			r.Reason,
+			q.AccountID,
+			q.Date,
+			q.Region,
+			q.UsageType,
+			q.Platform,
+			q.CacheEngine,
+			q.DatabaseEngine,
+			q.InstanceNum,
+			r.Output.UsageType,
+			r.Output.InstanceNum,
+		)
+	}
+}
+
+// inRegion returns the quantities of region.
+func inRegion(quantity []usage.Quantity, region []string) []usage.Quantity {
+	in := make(map[string]bool)
+	for _, r := range region {
+		in[r] = true
+	}
+
+	out := make([]usage.Quantity, 0)
+	for _, q := range quantity {
+		if in[q.Region] {
+			out = append(out, q)
+		}
+	}
+
+	return out
+}
+
+// coverage outputs the quantities matched to no price and the coverage of the on-demand cost to stderr.
+func coverage(c hermes.Coverage, format string) {
+	if format == "csv" {
+		fmt.Println("account_id, date, region, usage_type, os/engine, instance_hour, on_demand, cost, estimated")
+	}
+
+	for _, u := range c.Unmatched {
+		if format == "json" {
+			bytes, err := json.Marshal(u)
+			if err != nil {
+				fmt.Printf("marshal: %v", err)
+				os.Exit(1)
+			}
+
+			fmt.Println(string(bytes))
+			continue
+		}
+
+		q := u.Quantity
+		fmt.Printf(
+			"%s, %s, %s, %s, %s%s%s, %.3f, %.3f, %.3f, %t\n",
+			q.AccountID,
+			q.Date,
+			q.Region,
+			q.UsageType,
+			q.Platform,
+			q.CacheEngine,
+			q.DatabaseEngine,
+			q.InstanceHour,
+			u.OnDemand,
+			u.Cost,
+			u.Estimated,
+		)
+	}
+
+	fmt.Fprintf(
+		os.Stderr,
+		"coverage: %.2f%% (matched: %.3f hours, %.3f USD, unmatched: %.3f hours, %.3f USD)\n",
+		c.Rate()*100,
+		c.MatchedHour,
+		c.MatchedCost,
+		c.UnmatchedHour,
+		c.UnmatchedCost,
+	)
+}
@@ -5,8 +5,13 @@ import (
 	"os"
 
 	"github.com/itsubaki/hermes/cmd"
+	"github.com/itsubaki/hermes/cmd/cache"
+	"github.com/itsubaki/hermes/cmd/denormalize"
+	"github.com/itsubaki/hermes/cmd/explain"
 	"github.com/itsubaki/hermes/cmd/fetch"
+	"github.com/itsubaki/hermes/cmd/imports"
 	"github.com/itsubaki/hermes/cmd/pricing"
+	"github.com/itsubaki/hermes/cmd/query"
 	"github.com/itsubaki/hermes/cmd/usage"
 	"github.com/urfave/cli"
 )
@@ -25,33 +30,25 @@ func New(version string) *cli.App {
 			Name:  "dir, d",
 			Value: "/var/tmp/hermes",
 		},
+		cli.StringFlag{
+			Name:  "storage, s",
+			Value: "file",
+			Usage: "file, sqlite",
+		},
+		cli.BoolFlag{
+			Name:  "gzip, z",
+			Usage: "write gzip compressed files",
+		},
+		cli.StringFlag{
+			Name:  "mapping",
+			Usage: "json file of usage platform, database engine and cache engine to pricing attributes, added to the built-in ones",
+		},
 	}
 
 	region := cli.StringSliceFlag{
-		Name: "region, r",
-		Value: &cli.StringSlice{
-			//"ap-east-1",
-			//"ap-south-1",
-			"ap-northeast-1",
-			//"ap-northeast-2",
-			//"ap-northeast-3",
-			//"ap-southeast-1",
-			//"ap-southeast-2",
-			//"eu-north-1",
-			//"eu-west-1",
-			//"eu-west-2",
-			//"eu-west-3",
-			//"eu-central-1",
-			//"us-east-1",
-			//"us-east-2",
-			//"us-west-1",
-			//"us-west-2",
-			//"us-gov-east-1",
-			//"us-gov-west-1",
-			//"ca-central-1",
-			//"sa-east-1",
-			//"me-south-1",
-		},
+		Name:  "region, r",
+		Value: &cli.StringSlice{"ap-northeast-1"},
+		Usage: "region code, or all for the regions listed in the region index (fetch) or cached",
 	}
 
 	format := cli.StringFlag{
@@ -67,6 +64,38 @@ func New(version string) *cli.App {
 		Usage:   "fetch aws pricing, usage",
 		Flags: []cli.Flag{
 			region,
+			cli.StringFlag{
+				Name:   "endpoint",
+				Usage:  "price list api endpoint or a mirror serving /offers/v1.0/aws/{service}/current/region_index.json (default: https://pricing.us-east-1.amazonaws.com)",
+				EnvVar: "HERMES_PRICING_ENDPOINT",
+			},
+			cli.BoolFlag{
+				Name:  "update",
+				Usage: "fetch cached pricing again, keeping a snapshot when the price list version changed",
+			},
+			cli.IntFlag{
+				Name:  "parallel, p",
+				Value: 4,
+				Usage: "number of offer files, cost explorer requests fetched concurrently",
+			},
+			cli.Float64Flag{
+				Name:  "rate-limit",
+				Value: 5,
+				Usage: "cost explorer requests per second",
+			},
+			cli.IntFlag{
+				Name:  "max-retries",
+				Value: 5,
+				Usage: "retries of a throttled cost explorer request with exponential backoff",
+			},
+			cli.IntFlag{
+				Name:  "max-ce-requests",
+				Usage: "maximum number of cost explorer requests (0: unlimited)",
+			},
+			cli.BoolFlag{
+				Name:  "consolidated",
+				Usage: "fetch usage of all linked accounts with requests grouped by LINKED_ACCOUNT",
+			},
 		},
 	}
 
@@ -79,6 +108,64 @@ func New(version string) *cli.App {
 			region,
 			format,
 		},
+		Subcommands: []cli.Command{
+			{
+				Name:   "history",
+				Action: pricing.History,
+				Usage:  "output snapshots of pricing and their price list versions",
+				Flags: []cli.Flag{
+					region,
+				},
+			},
+			{
+				Name:   "diff",
+				Action: pricing.Diff,
+				Usage:  "output added, removed and changed prices between two snapshots",
+				Flags: []cli.Flag{
+					region,
+					format,
+					cli.StringFlag{
+						Name:  "from",
+						Usage: "snapshot or price list version (default: the snapshot before --to)",
+					},
+					cli.StringFlag{
+						Name:  "to",
+						Usage: "snapshot or price list version (default: latest snapshot)",
+					},
+				},
+			},
+			{
+				Name:   "validate",
+				Action: pricing.Validate,
+				Usage:  "output duplicated prices and the rule selecting the canonical one",
+				Flags: []cli.Flag{
+					region,
+					format,
+					cli.BoolFlag{
+						Name:  "strict",
+						Usage: "exit with status 1 when the canonical price is ambiguous",
+					},
+				},
+			},
+			{
+				Name:   "import",
+				Action: pricing.Import,
+				Usage:  "import pricing from a downloaded offer file (json, csv, .gz)",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "service",
+						Usage: "AmazonEC2, AmazonRDS, AmazonElastiCache, AmazonRedshift, AmazonES, AmazonDynamoDB, AmazonMemoryDB",
+					},
+					cli.StringFlag{
+						Name: "region, r",
+					},
+					cli.StringFlag{
+						Name:  "file, f",
+						Usage: "offer file",
+					},
+				},
+			},
+		},
 	}
 
 	usage := cli.Command{
@@ -93,6 +180,30 @@ func New(version string) *cli.App {
 				Name:  "normalize, n",
 				Usage: "output normalized usage",
 			},
+			cli.BoolFlag{
+				Name:  "units",
+				Usage: "output normalized units per hour of each instance family and month with the size mix",
+			},
+			cli.BoolFlag{
+				Name:  "lenient",
+				Usage: "skip prices with an invalid normalization size factor or offer term code instead of failing",
+			},
+			cli.BoolFlag{
+				Name:  "report",
+				Usage: "output whether each usage was normalized or left as-is, and why (with --normalize), or the usage skipped in the normalized units (with --units)",
+			},
+			cli.BoolFlag{
+				Name:  "unmatched",
+				Usage: "output the usage matched to no price with the estimated on-demand cost, and the coverage to stderr",
+			},
+			cli.StringFlag{
+				Name:  "rules",
+				Usage: "json file of instance size flexibility rules replacing the built-in ones",
+			},
+			cli.StringFlag{
+				Name:  "date",
+				Usage: "purchase date (YYYY-MM-DD) of the flexibility rules in effect (default: today)",
+			},
 			cli.BoolFlag{
 				Name:  "merge, m",
 				Usage: "output merged usage group by linked account",
@@ -108,10 +219,143 @@ func New(version string) *cli.App {
 		},
 	}
 
+	cache := cli.Command{
+		Name:    "cache",
+		Aliases: []string{"c"},
+		Usage:   "inspect, verify and prune cached pricing, usage",
+		Subcommands: []cli.Command{
+			{
+				Name:   "ls",
+				Action: cache.List,
+				Usage:  "list cached files with rows, versions and checksums",
+				Flags: []cli.Flag{
+					format,
+				},
+			},
+			{
+				Name:   "verify",
+				Action: cache.Verify,
+				Usage:  "verify cached files can be deserialized",
+			},
+			{
+				Name:   "prune",
+				Action: cache.Prune,
+				Usage:  "remove corrupted, temporary files and archived prices of no snapshot",
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "output files to be removed without removing",
+					},
+					cli.IntFlag{
+						Name:  "older-than",
+						Usage: "also remove usage of the months older than the count of months before this month",
+					},
+				},
+			},
+			{
+				Name:      "rm",
+				Action:    cache.Remove,
+				Usage:     "remove cached files",
+				ArgsUsage: "[pricing/<region>|history/<region>|usage/<YYYY-MM>|dimension/<YYYY-MM>]...",
+			},
+		},
+	}
+
+	query := cli.Command{
+		Name:      "query",
+		Aliases:   []string{"q"},
+		Action:    query.Action,
+		Usage:     "query pricing, usage stored in sqlite",
+		ArgsUsage: "\"SQL\"",
+		Flags: []cli.Flag{
+			format,
+		},
+	}
+
+	imports := cli.Command{
+		Name:  "import",
+		Usage: "import usage from local files",
+		Subcommands: []cli.Command{
+			{
+				Name:      "cur",
+				Action:    imports.CUR,
+				Usage:     "import usage from cost and usage report csv(.gz), parquet files",
+				ArgsUsage: "<file or directory>",
+			},
+		},
+	}
+
+	denormalize := cli.Command{
+		Name:   "denormalize",
+		Action: denormalize.Action,
+		Usage:  "convert the normalized recommendation of each purchase in stdin to instance sizes",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "strategy",
+				Value: "largest",
+				Usage: "largest (largest size first), current (the size mix of the current usage, then largest)",
+			},
+			cli.IntFlag{
+				Name:  "max-count",
+				Usage: "maximum count of each size (0: unlimited)",
+			},
+			cli.StringFlag{
+				Name:  "rules",
+				Usage: "json file of instance size flexibility rules replacing the built-in ones",
+			},
+			cli.StringFlag{
+				Name:  "date",
+				Usage: "purchase date (YYYY-MM-DD) of the flexibility rules in effect (default: today)",
+			},
+		},
+	}
+
+	explain := cli.Command{
+		Name:   "explain",
+		Action: explain.Action,
+		Usage:  "output how a usage is matched to the cached pricing and normalized",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "region, r",
+				Value: "ap-northeast-1",
+			},
+			format,
+			cli.StringFlag{
+				Name:  "usage-type, u",
+				Usage: "APN1-BoxUsage:m5.xlarge",
+			},
+			cli.StringFlag{
+				Name:  "platform, p",
+				Usage: "usage platform: Linux/UNIX, Windows (BYOL)",
+			},
+			cli.StringFlag{
+				Name:  "database-engine",
+				Usage: "usage database engine: Aurora MySQL, Oracle (SE2)",
+			},
+			cli.StringFlag{
+				Name:  "cache-engine",
+				Usage: "usage cache engine: Redis",
+			},
+			cli.StringFlag{
+				Name:  "rules",
+				Usage: "json file of instance size flexibility rules replacing the built-in ones",
+			},
+			cli.StringFlag{
+				Name:  "date",
+				Usage: "purchase date (YYYY-MM-DD) of the flexibility rules in effect (default: today)",
+			},
+		},
+	}
+
 	app.Commands = []cli.Command{
 		fetch,
 		pricing,
 		usage,
+		cache,
+		query,
+		imports,
+		denormalize,
+		explain,
 	}
 
 	return app
@@ -32,8 +32,15 @@ func TestPackage(t *testing.T) {
 		fmt.Errorf("desirialize pricing: %v", err)
 	}
 
-	family := pricing.Family(plist)
-	mini := pricing.Minimum(family, plist)
+	family, err := pricing.Family(plist)
+	if err != nil {
+		t.Errorf("family: %v", err)
+	}
+
+	mini, err := pricing.Minimum(family, plist)
+	if err != nil {
+		t.Errorf("minimum: %v", err)
+	}
 
 	date := usage.Last12Months()
 	forecast, err := usage.Deserialize("/var/tmp/hermes", date)
@@ -41,22 +48,39 @@ func TestPackage(t *testing.T) {
 		t.Errorf("deserialize usage: %v", err)
 	}
 
-	normalized := hermes.Normalize(forecast, mini)
+	normalized, err := hermes.Normalize(forecast, mini)
+	if err != nil {
+		t.Errorf("normalize: %v", err)
+	}
+
+	coverage := hermes.Cover(forecast, plist, hermes.Mapping{})
+	for _, u := range coverage.Unmatched {
+		t.Logf("unmatched: %v", u.Quantity)
+	}
+	t.Logf("coverage: %.2f%%", coverage.Rate()*100)
+
 	merged := usage.MergeOverall(normalized)
 	monthly := usage.Monthly(merged)
 
-	for _, p := range price {
-		for k := range monthly {
-			if len(monthly[k][0].Platform) > 0 {
-				if p.UsageType != monthly[k][0].UsageType || p.OperatingSystem != hermes.OperatingSystem[monthly[k][0].Platform] {
-					continue
-				}
+	keys := usage.SortedKey(monthly)
+	rows := make([]usage.Quantity, 0)
+	for _, k := range keys {
+		rows = append(rows, monthly[k][0])
+	}
+
+	matched := hermes.Cover(rows, price, hermes.Mapping{})
+	for _, u := range matched.Unmatched {
+		t.Logf("no price: %v", u.Quantity)
+	}
+
+	for _, k := range keys {
+		for _, p := range price {
+			if p.UsageType != monthly[k][0].UsageType {
+				continue
 			}
 
-			if len(monthly[k][0].Platform) < 1 {
-				if fmt.Sprintf("%s%s%s", p.UsageType, p.CacheEngine, p.DatabaseEngine) != k {
-					continue
-				}
+			if !hermes.Match(monthly[k][0], p) {
+				continue
 			}
 
 			q, _ := hermes.BreakEvenPoint(monthly[k], p)
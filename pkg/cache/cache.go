@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+type Header struct {
+	SchemaVersion int    `json:"schema_version"`
+	HermesVersion string `json:"hermes_version,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty"`
+	Source        string `json:"source,omitempty"`
+	Compressed    bool   `json:"-"`
+}
+
+type Option struct {
+	HermesVersion string
+	Source        string
+	Compress      bool
+	Overwrite     bool
+}
+
+type envelope struct {
+	Header
+	Data json.RawMessage `json:"data"`
+}
+
+// Encode wraps v in a versioned envelope, gzip compressed when opt.Compress is set.
+func Encode(schema int, v interface{}, opt Option) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %v", err)
+	}
+
+	b, err := json.Marshal(envelope{
+		Header: Header{
+			SchemaVersion: schema,
+			HermesVersion: opt.HermesVersion,
+			CreatedAt:     time.Now().UTC().Format(time.RFC3339),
+			Source:        opt.Source,
+		},
+		Data: data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal envelope: %v", err)
+	}
+
+	if !opt.Compress {
+		return b, nil
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("gzip: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decode returns the header and raw data of b.
+// Files written before the envelope was introduced are a bare json array and are returned as schema version 0.
+func Decode(b []byte) (Header, json.RawMessage, error) {
+	var h Header
+	if len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return h, nil, fmt.Errorf("gzip: %v", err)
+		}
+		defer r.Close()
+
+		read, err := ioutil.ReadAll(r)
+		if err != nil {
+			return h, nil, fmt.Errorf("gunzip: %v", err)
+		}
+
+		b, h.Compressed = read, true
+	}
+
+	trimmed := bytes.TrimSpace(b)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return h, json.RawMessage(trimmed), nil
+	}
+
+	var e envelope
+	if err := json.Unmarshal(trimmed, &e); err != nil {
+		return h, nil, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	if e.SchemaVersion < 1 {
+		return h, nil, fmt.Errorf("schema version not found")
+	}
+
+	e.Header.Compressed = h.Compressed
+	return e.Header, e.Data, nil
+}
+
+// Check returns an error when h was written with a newer schema than supported.
+func Check(h Header, supported int) error {
+	if h.SchemaVersion <= supported {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"incompatible schema version %d (supported up to %d), written by hermes %s. upgrade hermes or remove the file",
+		h.SchemaVersion,
+		supported,
+		h.HermesVersion,
+	)
+}
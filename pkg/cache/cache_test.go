@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	cases := []struct {
+		Option Option
+	}{
+		{Option{HermesVersion: "test", Source: "example", Compress: false}},
+		{Option{HermesVersion: "test", Source: "example", Compress: true}},
+	}
+
+	for _, tt := range cases {
+		b, err := Encode(1, []string{"foo", "bar"}, tt.Option)
+		if err != nil {
+			t.Errorf("encode: %v", err)
+		}
+
+		h, data, err := Decode(b)
+		if err != nil {
+			t.Errorf("decode: %v", err)
+		}
+
+		if h.SchemaVersion != 1 || h.HermesVersion != "test" || h.Source != "example" || len(h.CreatedAt) < 1 {
+			t.Errorf("header: %v", h)
+		}
+
+		if h.Compressed != tt.Option.Compress {
+			t.Errorf("expected: %v, actual: %v", tt.Option.Compress, h.Compressed)
+		}
+
+		if string(data) != `["foo","bar"]` {
+			t.Errorf("data: %s", data)
+		}
+	}
+}
+
+func TestDecodeLegacy(t *testing.T) {
+	h, data, err := Decode([]byte(` [{"usage_type":"APN1-BoxUsage:c4.large"}]`))
+	if err != nil {
+		t.Errorf("decode: %v", err)
+	}
+
+	if h.SchemaVersion != 0 {
+		t.Errorf("schema version: %v", h.SchemaVersion)
+	}
+
+	if !strings.HasPrefix(string(data), "[") {
+		t.Errorf("data: %s", data)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	cases := []string{
+		"",
+		"garbage",
+		`{"data":[]}`,
+		"\x1f\x8bgarbage",
+	}
+
+	for _, tt := range cases {
+		if _, _, err := Decode([]byte(tt)); err == nil {
+			t.Errorf("expected error: %q", tt)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		Header    Header
+		Supported int
+		Error     bool
+	}{
+		{Header{SchemaVersion: 0}, 1, false},
+		{Header{SchemaVersion: 1}, 1, false},
+		{Header{SchemaVersion: 2, HermesVersion: "future"}, 1, true},
+	}
+
+	for _, tt := range cases {
+		err := Check(tt.Header, tt.Supported)
+		if (err != nil) != tt.Error {
+			t.Errorf("expected: %v, actual: %v", tt.Error, err)
+		}
+	}
+}
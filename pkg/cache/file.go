@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes b to a temporary file in the same directory and renames it to file,
+// so that readers never see a partially written file.
+func WriteFile(file string, b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(file), fmt.Sprintf(".%s.*.tmp", filepath.Base(file)))
+	if err != nil {
+		return fmt.Errorf("create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %v", tmp.Name(), err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync %s: %v", tmp.Name(), err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close %s: %v", tmp.Name(), err)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("chmod %s: %v", tmp.Name(), err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("rename %s: %v", tmp.Name(), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := fmt.Sprintf("%s/ap-northeast-1.out", dir)
+	for _, s := range []string{"foo", "bar"} {
+		if err := WriteFile(file, []byte(s)); err != nil {
+			t.Errorf("write file: %v", err)
+		}
+
+		read, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Errorf("read file: %v", err)
+		}
+
+		if string(read) != s {
+			t.Errorf("expected: %v, actual: %v", s, string(read))
+		}
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Errorf("read dir: %v", err)
+	}
+
+	if len(files) != 1 {
+		t.Errorf("temporary file remains: %v", files)
+	}
+
+	if err := WriteFile(fmt.Sprintf("%s/notfound/foo.out", dir), []byte("foo")); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+)
+
+type FileLock struct {
+	file *os.File
+}
+
+// Lock takes an exclusive lock on {dir}/.lock, with flock on unix and LockFileEx on windows.
+// When another process holds the lock, wait is called with the lock file and Lock blocks until it is released.
+func Lock(dir string, wait func(file string)) (*FileLock, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		os.MkdirAll(dir, os.ModePerm)
+	}
+
+	file := fmt.Sprintf("%s/.lock", dir)
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %v", file, err)
+	}
+
+	err = flock(f, false)
+	if err != nil && !wouldBlock(err) {
+		f.Close()
+		return nil, fmt.Errorf("lock %s: %v", file, err)
+	}
+
+	if err != nil {
+		if wait != nil {
+			wait(file)
+		}
+
+		if err := flock(f, true); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %v", file, err)
+		}
+	}
+
+	if err := f.Truncate(0); err == nil {
+		fmt.Fprintf(f, "%d\n", os.Getpid())
+	}
+
+	return &FileLock{file: f}, nil
+}
+
+func (l *FileLock) Unlock() error {
+	if err := funlock(l.file); err != nil {
+		l.file.Close()
+		return fmt.Errorf("unlock %s: %v", l.file.Name(), err)
+	}
+
+	return l.file.Close()
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+// +build !windows
+
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	l0, err := Lock(dir, nil)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	waiting := make(chan string, 1)
+	locked := make(chan *FileLock)
+	go func() {
+		l1, err := Lock(dir, func(file string) { waiting <- file })
+		if err != nil {
+			t.Errorf("lock: %v", err)
+		}
+		locked <- l1
+	}()
+
+	select {
+	case <-waiting:
+	case <-locked:
+		t.Fatalf("lock acquired while held")
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if err := l0.Unlock(); err != nil {
+		t.Errorf("unlock: %v", err)
+	}
+
+	select {
+	case l1 := <-locked:
+		if err := l1.Unlock(); err != nil {
+			t.Errorf("unlock: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+// +build !windows
+
+package cache
+
+import (
+	"os"
+	"syscall"
+)
+
+func flock(f *os.File, block bool) error {
+	how := syscall.LOCK_EX
+	if !block {
+		how = how | syscall.LOCK_NB
+	}
+
+	return syscall.Flock(int(f.Fd()), how)
+}
+
+func funlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
+
+func wouldBlock(err error) bool {
+	return err == syscall.EWOULDBLOCK
+}
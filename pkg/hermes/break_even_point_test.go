@@ -43,3 +43,30 @@ func TestBreakEvenPoint(t *testing.T) {
 		t.Errorf("%v", q.InstanceNum)
 	}
 }
+
+func TestBreakEvenPointCapacity(t *testing.T) {
+	// the rates of a read capacity unit.
+	price := pricing.Price{
+		Region:              "ap-northeast-1",
+		UsageType:           "APN1-ReadCapacityUnit-Hrs",
+		LeaseContractLength: "1yr",
+		PurchaseOption:      "Heavy Utilization",
+		OnDemand:            0.0001484,
+		ReservedQuantity:    0.348,
+		ReservedHrs:         0.0000680,
+	}
+
+	forecast := make([]usage.Quantity, 0)
+	for i := 0; i < 12; i++ {
+		forecast = append(forecast, usage.Quantity{UsageType: "APN1-ReadCapacityUnit-Hrs", InstanceNum: float64(1000 + 100*i)})
+	}
+
+	if price.BreakEvenPoint() != 9 {
+		t.Errorf("break even point: %v", price.BreakEvenPoint())
+	}
+
+	q, _ := BreakEvenPoint(forecast, price)
+	if q.InstanceNum != 1300 || q.UsageType != "APN1-ReadCapacityUnit-Hrs" {
+		t.Errorf("%v", q)
+	}
+}
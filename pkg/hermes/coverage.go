@@ -0,0 +1,89 @@
+package hermes
+
+import (
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+// Cost is the on-demand cost of a quantity.
+type Cost struct {
+	Quantity  usage.Quantity `json:"quantity"`
+	SKU       string         `json:"sku,omitempty"` // the matched price. empty when no price is matched
+	OnDemand  float64        `json:"on_demand"`     // hourly on-demand rate
+	Cost      float64        `json:"cost"`          // OnDemand × InstanceHour
+	Estimated bool           `json:"estimated,omitempty"`
+}
+
+// Coverage is the usage matched to a price and the usage not.
+type Coverage struct {
+	Matched       []Cost  `json:"matched"`
+	Unmatched     []Cost  `json:"unmatched"`
+	MatchedHour   float64 `json:"matched_hour"`
+	UnmatchedHour float64 `json:"unmatched_hour"`
+	MatchedCost   float64 `json:"matched_cost"`
+	UnmatchedCost float64 `json:"unmatched_cost"`
+}
+
+// Rate returns the fraction of the on-demand cost of the usage matched to a price.
+func (c Coverage) Rate() float64 {
+	total := c.MatchedCost + c.UnmatchedCost
+	if total == 0 {
+		return 0
+	}
+
+	return c.MatchedCost / total
+}
+
+// Cover matches each quantity to a price of plist by m.
+// A quantity matched to a price without an on-demand rate is matched with zero cost.
+// The cost of a quantity matched to no price is estimated with the lowest on-demand rate
+// of the region and usage type in plist, or zero when the usage type has no price.
+func Cover(quantity []usage.Quantity, plist []pricing.Price, m Mapping) Coverage {
+	plist, _ = pricing.Dedupe(plist)
+
+	index := make(map[string][]pricing.Price)
+	for _, p := range plist {
+		k := p.Region + p.UsageType
+		index[k] = append(index[k], p)
+	}
+
+	var out Coverage
+	for _, q := range quantity {
+		var matched *pricing.Price
+		var lowest *pricing.Price
+
+		price := index[q.Region+q.UsageType]
+		for i := range price {
+			if matched == nil && m.Match(q, price[i]) {
+				matched = &price[i]
+			}
+
+			if price[i].OnDemand <= 0 {
+				continue
+			}
+
+			if lowest == nil || price[i].OnDemand < lowest.OnDemand {
+				lowest = &price[i]
+			}
+		}
+
+		if matched != nil {
+			c := Cost{Quantity: q, SKU: matched.SKU, OnDemand: matched.OnDemand, Cost: matched.OnDemand * q.InstanceHour}
+			out.Matched = append(out.Matched, c)
+			out.MatchedHour = out.MatchedHour + q.InstanceHour
+			out.MatchedCost = out.MatchedCost + c.Cost
+			continue
+		}
+
+		c := Cost{Quantity: q}
+		if lowest != nil {
+			c.OnDemand, c.Cost, c.Estimated = lowest.OnDemand, lowest.OnDemand*q.InstanceHour, true
+		}
+
+		out.Unmatched = append(out.Unmatched, c)
+		out.UnmatchedHour = out.UnmatchedHour + q.InstanceHour
+		out.UnmatchedCost = out.UnmatchedCost + c.Cost
+	}
+
+	return out
+}
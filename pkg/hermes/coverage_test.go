@@ -0,0 +1,44 @@
+package hermes
+
+import (
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestCover(t *testing.T) {
+	plist := []pricing.Price{
+		{SKU: "SKU1", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", OperatingSystem: "Linux", PreInstalled: "NA", OnDemand: 0.1},
+		{SKU: "SKU2", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", OperatingSystem: "Windows", PreInstalled: "NA", OnDemand: 0.2},
+		{SKU: "SKU3", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", OperatingSystem: "RHEL", PreInstalled: "NA"},
+	}
+
+	quantity := []usage.Quantity{
+		{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", InstanceHour: 100},
+		{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "SUSE Linux", InstanceHour: 100},
+		{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:m4.large", Platform: "Linux/UNIX", InstanceHour: 50},
+		{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Red Hat Enterprise Linux", InstanceHour: 10},
+	}
+
+	c := Cover(quantity, plist, Mapping{})
+	if len(c.Matched) != 2 || c.Matched[0].SKU != "SKU1" || c.MatchedCost != 10 || c.MatchedHour != 110 {
+		t.Errorf("matched: %v", c)
+	}
+
+	if c.Matched[1].SKU != "SKU3" || c.Matched[1].Cost != 0 {
+		t.Errorf("matched: %v", c.Matched[1])
+	}
+
+	if len(c.Unmatched) != 2 || c.UnmatchedHour != 150 || c.UnmatchedCost != 10 {
+		t.Errorf("unmatched: %v", c)
+	}
+
+	if !c.Unmatched[0].Estimated || c.Unmatched[0].OnDemand != 0.1 || c.Unmatched[1].Estimated {
+		t.Errorf("estimated: %v", c.Unmatched)
+	}
+
+	if c.Rate() != 0.5 {
+		t.Errorf("rate: %v", c.Rate())
+	}
+}
@@ -0,0 +1,52 @@
+package hermes
+
+import (
+	"strings"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+)
+
+const (
+	SingleAZUsage = "InstanceUsage"
+	MultiAZUsage  = "Multi-AZUsage"
+)
+
+// https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_WorkingWithReservedDBInstances.html
+// A Multi-AZ deployment uses twice the normalized units of a Single-AZ deployment.
+
+// DeploymentFactor returns the normalized units of a deployment of usageType relative to a Single-AZ deployment.
+func DeploymentFactor(usageType string) float64 {
+	if strings.Contains(usageType, MultiAZUsage) {
+		return 2
+	}
+
+	return 1
+}
+
+// SingleAZ returns the Single-AZ usage type of the Multi-AZ usageType.
+func SingleAZ(usageType string) string {
+	return strings.Replace(usageType, MultiAZUsage, SingleAZUsage, 1)
+}
+
+// databaseMinimum returns the minimum price of t and the deployment factor of t to it.
+// The minimum of a Multi-AZ price is the Single-AZ one, when it is found in mini.
+func databaseMinimum(t pricing.Tuple, mini map[string]pricing.Tuple) (pricing.Price, float64) {
+	if !strings.Contains(t.Price.UsageType, MultiAZUsage) {
+		return t.Minimum, 1
+	}
+
+	s, ok := mini[pricing.TupleKey(
+		SingleAZ(t.Price.UsageType),
+		t.Price.OperatingSystem,
+		t.Price.PreInstalled,
+		t.Price.LicenseModel,
+		t.Price.CacheEngine,
+		t.Price.DatabaseEngine,
+		t.Price.DatabaseEdition,
+	)]
+	if !ok {
+		return t.Minimum, 1
+	}
+
+	return s.Minimum, DeploymentFactor(t.Price.UsageType)
+}
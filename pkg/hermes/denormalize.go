@@ -0,0 +1,168 @@
+package hermes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+// Strategy of Denormalize.
+const (
+	LargestFirst = "largest" // as many of the largest size as possible
+	CurrentMix   = "current" // the sizes in the proportion of the current usage, then LargestFirst
+)
+
+type DenormalizeInput struct {
+	Quantity usage.Quantity   // normalized to the smallest size of its family
+	Size     []pricing.Price  // the sizes of the family. see Sizes.
+	Strategy string           // LargestFirst when empty
+	Current  []usage.Quantity // the usage in real sizes for CurrentMix
+	MaxCount int              // the maximum count of each size. 0 is unlimited.
+}
+
+// Sizes returns the prices of the family of p in plist, one for each size in descending order of size.
+// plist is expected to be deduped with pricing.Dedupe.
+// Prices without a normalization size factor or size flexibility under rules on date (bare metal) are ignored.
+// rules is DefaultRules when empty, and date is today when zero.
+func Sizes(plist []pricing.Price, p pricing.Price, rules Rules, date time.Time) []pricing.Price {
+	if len(rules) < 1 {
+		rules = DefaultRules
+	}
+
+	if date.IsZero() {
+		date, _ = ParseDate("")
+	}
+
+	size := make(map[string]pricing.Price)
+	factor := make(map[string]float64)
+	for _, s := range plist {
+		if !sameFamily(s, p) || len(rules.Inflexibility(s, date)) > 0 {
+			continue
+		}
+
+		f, ok, err := pricing.SizeFactor(s)
+		if err != nil || !ok {
+			continue
+		}
+
+		size[s.UsageType], factor[s.UsageType] = s, f
+	}
+
+	out := make([]pricing.Price, 0)
+	for _, s := range size {
+		out = append(out, s)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].UsageType < out[j].UsageType })
+	sort.SliceStable(out, func(i, j int) bool { return factor[out[i].UsageType] > factor[out[j].UsageType] })
+
+	return out
+}
+
+// Denormalize converts the normalized in.Quantity to the sizes of in.Size with the same normalized units.
+// It returns the quantities in descending order of size and the remainder,
+// the count of the smallest size not converted because of in.MaxCount.
+func Denormalize(in *DenormalizeInput) ([]usage.Quantity, float64, error) {
+	if len(in.Strategy) > 0 && in.Strategy != LargestFirst && in.Strategy != CurrentMix {
+		return nil, 0, fmt.Errorf("unknown strategy: %s", in.Strategy)
+	}
+
+	factor := make(map[string]float64)
+	for _, s := range in.Size {
+		f, ok, err := pricing.SizeFactor(s)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %v", s.UsageType, err)
+		}
+
+		if !ok {
+			return nil, 0, fmt.Errorf("%s: normalization size factor not found", s.UsageType)
+		}
+
+		factor[s.UsageType] = f
+	}
+
+	base, ok := factor[in.Quantity.UsageType]
+	if !ok {
+		return nil, 0, fmt.Errorf("size of %s not found", in.Quantity.UsageType)
+	}
+
+	units := in.Quantity.InstanceNum * base
+	count := make(map[string]float64)
+	add := func(usageType string, target float64) {
+		n := math.Floor(target/factor[usageType] + 1e-9)
+		if in.MaxCount > 0 {
+			n = math.Min(n, float64(in.MaxCount)-count[usageType])
+		}
+
+		if n <= 0 {
+			return
+		}
+
+		count[usageType] = count[usageType] + n
+		units = units - n*factor[usageType]
+	}
+
+	if in.Strategy == CurrentMix {
+		current, total := make(map[string]float64), 0.0
+		for _, q := range in.Current {
+			if f, ok := factor[q.UsageType]; ok {
+				current[q.UsageType] = current[q.UsageType] + q.InstanceNum*f
+				total = total + q.InstanceNum*f
+			}
+		}
+
+		all := units
+		for _, s := range in.Size {
+			if total > 0 {
+				add(s.UsageType, all*current[s.UsageType]/total)
+			}
+		}
+	}
+
+	for _, s := range in.Size {
+		add(s.UsageType, units)
+	}
+
+	out := make([]usage.Quantity, 0)
+	for _, s := range in.Size {
+		if count[s.UsageType] < 1 {
+			continue
+		}
+
+		q := in.Quantity
+		q.UsageType = s.UsageType
+		q.InstanceNum = count[s.UsageType]
+		q.InstanceHour = 0
+		out = append(out, q)
+	}
+
+	return out, units / base, nil
+}
+
+func sameFamily(a, b pricing.Price) bool {
+	family := func(p pricing.Price) string {
+		if strings.LastIndex(p.UsageType, ".") < 0 {
+			return p.UsageType
+		}
+
+		return p.UsageType[:strings.LastIndex(p.UsageType, ".")]
+	}
+
+	return family(a) == family(b) &&
+		a.Region == b.Region &&
+		a.OperatingSystem == b.OperatingSystem &&
+		a.PreInstalled == b.PreInstalled &&
+		a.CacheEngine == b.CacheEngine &&
+		a.DatabaseEngine == b.DatabaseEngine &&
+		a.DatabaseEdition == b.DatabaseEdition &&
+		(a.LicenseModel == pricing.LicenseModelBYOL) == (b.LicenseModel == pricing.LicenseModelBYOL) &&
+		a.Tenancy == b.Tenancy &&
+		a.LeaseContractLength == b.LeaseContractLength &&
+		a.PurchaseOption == b.PurchaseOption &&
+		a.OfferingClass == b.OfferingClass
+}
@@ -0,0 +1,111 @@
+package hermes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestDenormalize(t *testing.T) {
+	plist := make([]pricing.Price, 0)
+	for _, s := range []struct {
+		InstanceType string
+		Factor       string
+	}{
+		{"c4.large", "4"},
+		{"c4.xlarge", "8"},
+		{"c4.2xlarge", "16"},
+		{"c4.8xlarge", "64"},
+		{"m4.xlarge", "8"},
+	} {
+		plist = append(plist, pricing.Price{
+			SKU:                     s.InstanceType,
+			Region:                  "ap-northeast-1",
+			UsageType:               "APN1-BoxUsage:" + s.InstanceType,
+			InstanceType:            s.InstanceType,
+			OperatingSystem:         "Linux",
+			PreInstalled:            "NA",
+			Tenancy:                 "Shared",
+			NormalizationSizeFactor: s.Factor,
+		})
+	}
+
+	size := Sizes(plist, plist[0], nil, time.Time{})
+	if len(size) != 4 || size[0].InstanceType != "c4.8xlarge" || size[3].InstanceType != "c4.large" {
+		t.Fatalf("size: %v", size)
+	}
+
+	rules := Rules{{Service: EC2, InstanceType: "c4.8xlarge", Reason: "rule", EffectiveDate: "2019-08-01"}}
+	if s := Sizes(plist, plist[0], rules, time.Date(2019, 7, 31, 0, 0, 0, 0, time.UTC)); len(s) != 4 {
+		t.Errorf("size: %v", s)
+	}
+
+	if s := Sizes(plist, plist[0], rules, time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)); len(s) != 3 || s[0].InstanceType != "c4.2xlarge" {
+		t.Errorf("size: %v", s)
+	}
+
+	if _, _, err := Denormalize(&DenormalizeInput{Quantity: usage.Quantity{UsageType: "APN1-BoxUsage:c4.large"}, Size: size, Strategy: "smallest"}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	factor := map[string]float64{
+		"APN1-BoxUsage:c4.large":   4,
+		"APN1-BoxUsage:c4.xlarge":  8,
+		"APN1-BoxUsage:c4.2xlarge": 16,
+		"APN1-BoxUsage:c4.8xlarge": 64,
+	}
+
+	// 140 normalized units
+	normalized := usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", InstanceNum: 35}
+	current := []usage.Quantity{
+		{UsageType: "APN1-BoxUsage:c4.2xlarge", InstanceNum: 4},
+		{UsageType: "APN1-BoxUsage:c4.xlarge", InstanceNum: 8},
+	}
+
+	cases := []struct {
+		Strategy  string
+		MaxCount  int
+		Expected  map[string]float64
+		Remainder float64
+	}{
+		{LargestFirst, 0, map[string]float64{"APN1-BoxUsage:c4.8xlarge": 2, "APN1-BoxUsage:c4.xlarge": 1, "APN1-BoxUsage:c4.large": 1}, 0},
+		{LargestFirst, 1, map[string]float64{"APN1-BoxUsage:c4.8xlarge": 1, "APN1-BoxUsage:c4.2xlarge": 1, "APN1-BoxUsage:c4.xlarge": 1, "APN1-BoxUsage:c4.large": 1}, 12},
+		{CurrentMix, 0, map[string]float64{"APN1-BoxUsage:c4.2xlarge": 4, "APN1-BoxUsage:c4.xlarge": 9, "APN1-BoxUsage:c4.large": 1}, 0},
+	}
+
+	for _, c := range cases {
+		out, remainder, err := Denormalize(&DenormalizeInput{
+			Quantity: normalized,
+			Size:     size,
+			Strategy: c.Strategy,
+			Current:  current,
+			MaxCount: c.MaxCount,
+		})
+		if err != nil {
+			t.Fatalf("denormalize: %v", err)
+		}
+
+		if len(out) != len(c.Expected) || remainder != c.Remainder {
+			t.Errorf("%v: out: %v, remainder: %v", c.Strategy, out, remainder)
+		}
+
+		units := remainder * 4
+		for _, q := range out {
+			if q.InstanceNum != c.Expected[q.UsageType] || q.Platform != "Linux/UNIX" {
+				t.Errorf("%v: %v", c.Strategy, q)
+			}
+
+			units = units + q.InstanceNum*factor[q.UsageType]
+		}
+
+		if units != 140 {
+			t.Errorf("%v: units: %v", c.Strategy, units)
+		}
+	}
+
+	if _, _, err := Denormalize(&DenormalizeInput{Quantity: usage.Quantity{UsageType: "APN1-BoxUsage:m4.large"}, Size: size}); err == nil {
+		t.Errorf("expected error")
+	}
+}
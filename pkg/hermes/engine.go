@@ -0,0 +1,41 @@
+package hermes
+
+import "github.com/itsubaki/hermes/pkg/pricing"
+
+// DatabaseEngine is the AWS Pricing attributes of a Usage DatabaseEngine.
+type DatabaseEngine struct {
+	DatabaseEngine  string `json:"database_engine"`
+	DatabaseEdition string `json:"database_edition,omitempty"`
+	LicenseModel    string `json:"license_model,omitempty"`
+}
+
+/*
+DatabaseEngines returns AWS Pricing DatabaseEngine, DatabaseEdition and LicenseModel from Usage DatabaseEngine.
+"Oracle" and "SQL Server" without an edition are not in the table and are matched to no price.
+Add the edition of them with a Mapping.
+*/
+var DatabaseEngines = map[string]DatabaseEngine{
+	"Aurora":               {"Aurora MySQL", "", LicenseModelNotRequired},
+	"Aurora MySQL":         {"Aurora MySQL", "", LicenseModelNotRequired},
+	"Aurora PostgreSQL":    {"Aurora PostgreSQL", "", LicenseModelNotRequired},
+	"MySQL":                {"MySQL", "", LicenseModelNotRequired},
+	"MariaDB":              {"MariaDB", "", LicenseModelNotRequired},
+	"PostgreSQL":           {"PostgreSQL", "", LicenseModelNotRequired},
+	"Oracle (SE)":          {"Oracle", "Standard", LicenseModelIncluded},
+	"Oracle (SE1)":         {"Oracle", "Standard One", LicenseModelIncluded},
+	"Oracle (SE2)":         {"Oracle", "Standard Two", LicenseModelIncluded},
+	"Oracle (EE)":          {"Oracle", "Enterprise", pricing.LicenseModelBYOL}, // license included is not offered
+	"SQL Server (SE)":      {"SQL Server", "Standard", LicenseModelIncluded},
+	"SQL Server (EE)":      {"SQL Server", "Enterprise", LicenseModelIncluded},
+	"SQL Server (Web)":     {"SQL Server", "Web", LicenseModelIncluded},
+	"SQL Server (Express)": {"SQL Server", "Express", LicenseModelIncluded},
+}
+
+/*
+CacheEngines returns AWS Pricing CacheEngine from Usage CacheEngine.
+*/
+var CacheEngines = map[string]string{
+	"Redis":     "Redis",
+	"Memcached": "Memcached",
+	"Valkey":    "Valkey",
+}
@@ -0,0 +1,132 @@
+package hermes
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+type ExplainInput struct {
+	Quantity usage.Quantity
+	Price    []pricing.Price // the prices of the region of Quantity
+	Rules    Rules           // DefaultRules when empty
+	Date     time.Time       // the purchase date the rules in effect on. today when zero
+	Mapping  Mapping         // added to the built-in usage to pricing attribute mapping
+}
+
+// Explanation is how a quantity is matched to a price and normalized.
+type Explanation struct {
+	Quantity   usage.Quantity  `json:"quantity"`
+	Attributes Attributes      `json:"attributes"`
+	Status     string          `json:"status"`
+	Reason     string          `json:"reason,omitempty"`
+	SKU        string          `json:"sku,omitempty"`
+	Family     string          `json:"family,omitempty"`
+	Minimum    *pricing.Price  `json:"minimum,omitempty"`   // the smallest size of the family
+	Scale      float64         `json:"scale,omitempty"`     // the count of Minimum of one instance of Quantity
+	Offering   []pricing.Price `json:"offering,omitempty"`  // the prices matched to Quantity
+	Candidate  []Attributes    `json:"candidate,omitempty"` // the attributes of the prices of the usage type, when no price is matched
+}
+
+// Explain returns how in.Quantity is matched to in.Price and normalized.
+// The prices with an invalid normalization size factor or offer term code are skipped
+// and returned in a *pricing.InvalidError.
+func Explain(in *ExplainInput) (Explanation, error) {
+	q := in.Quantity
+	if q.InstanceNum == 0 {
+		q.InstanceNum = 1
+	}
+
+	invalid := make([]pricing.PriceError, 0)
+	collect := func(err error) {
+		if ierr, ok := err.(*pricing.InvalidError); ok {
+			invalid = append(invalid, ierr.Invalid...)
+		}
+	}
+
+	family, err := pricing.Family(in.Price)
+	collect(err)
+
+	mini, err := pricing.Minimum(family, in.Price)
+	collect(err)
+
+	result, err := NormalizeWithInput(&NormalizeInput{
+		Quantity: []usage.Quantity{q},
+		Minimum:  mini,
+		Rules:    in.Rules,
+		Date:     in.Date,
+		Mapping:  in.Mapping,
+	})
+	collect(err)
+
+	out := Explanation{
+		Quantity:   q,
+		Attributes: in.Mapping.Attribute(q),
+		Status:     result[0].Status,
+		Reason:     result[0].Reason,
+	}
+
+	plist, _ := pricing.Dedupe(in.Price)
+	seen := make(map[Attributes]bool)
+	for _, p := range plist {
+		if p.UsageType != q.UsageType {
+			continue
+		}
+
+		if in.Mapping.Match(q, p) {
+			out.Offering = append(out.Offering, p)
+			continue
+		}
+
+		a := Attributes{
+			OperatingSystem: p.OperatingSystem,
+			PreInstalled:    p.PreInstalled,
+			LicenseModel:    p.LicenseModel,
+			CacheEngine:     p.CacheEngine,
+			DatabaseEngine:  p.DatabaseEngine,
+			DatabaseEdition: p.DatabaseEdition,
+		}
+		if !seen[a] {
+			seen[a] = true
+			out.Candidate = append(out.Candidate, a)
+		}
+	}
+
+	sort.SliceStable(out.Offering, func(i, j int) bool { return out.Offering[i].PurchaseOption < out.Offering[j].PurchaseOption })
+	sort.SliceStable(out.Offering, func(i, j int) bool { return out.Offering[i].OfferingClass < out.Offering[j].OfferingClass })
+	sort.SliceStable(out.Offering, func(i, j int) bool {
+		return out.Offering[i].LeaseContractLength < out.Offering[j].LeaseContractLength
+	})
+
+	if len(out.Offering) > 0 {
+		out.Candidate = nil
+	}
+
+	v, ok := mini[in.Mapping.Key(q)]
+	if ok {
+		minimum := v.Minimum
+		if len(v.Price.DatabaseEngine) > 0 {
+			minimum, _ = databaseMinimum(v, mini)
+		}
+
+		out.SKU, out.Minimum = v.Price.SKU, &minimum
+
+		out.Family = SingleAZ(q.UsageType)
+		if strings.LastIndex(out.Family, ".") > 0 {
+			out.Family = out.Family[:strings.LastIndex(out.Family, ".")]
+		}
+	}
+
+	if out.Status == Normalized {
+		out.Scale = result[0].Output.InstanceNum / q.InstanceNum
+	}
+
+	if len(invalid) > 0 {
+		return out, &pricing.InvalidError{Invalid: invalid}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package hermes
+
+import (
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestExplain(t *testing.T) {
+	price := func(sku, usageType, os, factor string) pricing.Price {
+		return pricing.Price{
+			SKU:                     sku,
+			OfferTermCode:           "6QCMYABX3D",
+			Region:                  "ap-northeast-1",
+			UsageType:               usageType,
+			OperatingSystem:         os,
+			PreInstalled:            "NA",
+			LeaseContractLength:     "1yr",
+			PurchaseOption:          "All Upfront",
+			OfferingClass:           "standard",
+			NormalizationSizeFactor: factor,
+		}
+	}
+
+	plist := []pricing.Price{
+		price("SKU1", "APN1-BoxUsage:c4.large", "Linux", "4"),
+		price("SKU2", "APN1-BoxUsage:c4.2xlarge", "Linux", "16"),
+		price("SKU3", "APN1-BoxUsage:c4.2xlarge", "Windows", "16"),
+	}
+
+	e, err := Explain(&ExplainInput{
+		Quantity: usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.2xlarge", Platform: "Linux/UNIX"},
+		Price:    plist,
+	})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+
+	if e.Status != Normalized || e.SKU != "SKU2" || e.Family != "APN1-BoxUsage:c4" || e.Minimum.SKU != "SKU1" || e.Scale != 4 {
+		t.Errorf("explanation: %v", e)
+	}
+
+	if len(e.Offering) != 1 || e.Offering[0].SKU != "SKU2" || len(e.Candidate) != 0 {
+		t.Errorf("offering: %v", e.Offering)
+	}
+
+	e, err = Explain(&ExplainInput{
+		Quantity: usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.2xlarge", Platform: "SUSE Linux"},
+		Price:    plist,
+	})
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+
+	if e.Status != PriceNotFound || e.Minimum != nil || len(e.Offering) != 0 || len(e.Candidate) != 2 {
+		t.Errorf("explanation: %v", e)
+	}
+}
@@ -0,0 +1,164 @@
+package hermes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+// Attributes are the AWS Pricing attributes a Usage Quantity is matched to prices by.
+type Attributes struct {
+	OperatingSystem string `json:"operating_system,omitempty"`
+	PreInstalled    string `json:"pre_installed,omitempty"`
+	LicenseModel    string `json:"license_model,omitempty"`
+	CacheEngine     string `json:"cache_engine,omitempty"`
+	DatabaseEngine  string `json:"database_engine,omitempty"`
+	DatabaseEdition string `json:"database_edition,omitempty"`
+}
+
+// Attribute returns the AWS Pricing attributes of q by Platforms, DatabaseEngines and CacheEngines.
+// An engine not in the tables is the attribute as it is.
+func Attribute(q usage.Quantity) Attributes {
+	return Mapping{}.Attribute(q)
+}
+
+// Key returns the pricing.TupleKey of the price of q.
+func Key(q usage.Quantity) string {
+	return Mapping{}.Key(q)
+}
+
+// Match returns true when p is the price of q: the same region, usage type, platform and engine.
+func Match(q usage.Quantity, p pricing.Price) bool {
+	return Mapping{}.Match(q, p)
+}
+
+// MatchPlatform returns true when q is the usage of the region, platform and engine of p in any usage type.
+func MatchPlatform(q usage.Quantity, p pricing.Price) bool {
+	return Mapping{}.MatchPlatform(q, p)
+}
+
+// Mapping is the entries added to Platforms, DatabaseEngines and CacheEngines.
+// The entries of a Mapping override the built-in ones. The zero Mapping is the built-in ones.
+type Mapping struct {
+	Platform       map[string]Platform       `json:"platform,omitempty"`
+	DatabaseEngine map[string]DatabaseEngine `json:"database_engine,omitempty"`
+	CacheEngine    map[string]string         `json:"cache_engine,omitempty"`
+}
+
+// ReadMapping returns the mapping in the json file.
+func ReadMapping(file string) (Mapping, error) {
+	read, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Mapping{}, fmt.Errorf("read %s: %v", file, err)
+	}
+
+	var m Mapping
+	if err := json.Unmarshal(read, &m); err != nil {
+		return Mapping{}, fmt.Errorf("unmarshal %s: %v", file, err)
+	}
+
+	for k, v := range m.Platform {
+		if len(v.OperatingSystem) < 1 {
+			return Mapping{}, fmt.Errorf("platform %q: operating system not found", k)
+		}
+	}
+
+	for k, v := range m.DatabaseEngine {
+		if len(v.DatabaseEngine) < 1 {
+			return Mapping{}, fmt.Errorf("database engine %q: database engine not found", k)
+		}
+	}
+
+	for k, v := range m.CacheEngine {
+		if len(v) < 1 {
+			return Mapping{}, fmt.Errorf("cache engine %q: cache engine not found", k)
+		}
+	}
+
+	return m, nil
+}
+
+// Attribute returns the AWS Pricing attributes of q by m, Platforms, DatabaseEngines and CacheEngines.
+// An engine not in them is the attribute as it is.
+func (m Mapping) Attribute(q usage.Quantity) Attributes {
+	if len(q.DatabaseEngine) > 0 {
+		d, ok := m.DatabaseEngine[q.DatabaseEngine]
+		if !ok {
+			d, ok = DatabaseEngines[q.DatabaseEngine]
+		}
+
+		if !ok {
+			d = DatabaseEngine{DatabaseEngine: q.DatabaseEngine}
+		}
+
+		return Attributes{
+			LicenseModel:    d.LicenseModel,
+			DatabaseEngine:  d.DatabaseEngine,
+			DatabaseEdition: d.DatabaseEdition,
+		}
+	}
+
+	if len(q.CacheEngine) > 0 {
+		c, ok := m.CacheEngine[q.CacheEngine]
+		if !ok {
+			c, ok = CacheEngines[q.CacheEngine]
+		}
+
+		if !ok {
+			c = q.CacheEngine
+		}
+
+		return Attributes{CacheEngine: c}
+	}
+
+	p, ok := m.Platform[q.Platform]
+	if !ok {
+		p = Platforms[q.Platform]
+	}
+
+	return Attributes{
+		OperatingSystem: p.OperatingSystem,
+		PreInstalled:    p.PreInstalled,
+		LicenseModel:    p.LicenseModel,
+	}
+}
+
+// Key returns the pricing.TupleKey of the price of q by m.
+func (m Mapping) Key(q usage.Quantity) string {
+	a := m.Attribute(q)
+	return pricing.TupleKey(
+		q.UsageType,
+		a.OperatingSystem,
+		a.PreInstalled,
+		a.LicenseModel,
+		a.CacheEngine,
+		a.DatabaseEngine,
+		a.DatabaseEdition,
+	)
+}
+
+// Match returns true when p is the price of q by m: the same region, usage type, platform and engine.
+func (m Mapping) Match(q usage.Quantity, p pricing.Price) bool {
+	return q.UsageType == p.UsageType && m.MatchPlatform(q, p)
+}
+
+// MatchPlatform returns true when q is the usage of the region, platform and engine of p by m,
+// in any usage type (size and family).
+func (m Mapping) MatchPlatform(q usage.Quantity, p pricing.Price) bool {
+	if q.Region != p.Region {
+		return false
+	}
+
+	return m.Key(q) == pricing.TupleKey(
+		q.UsageType,
+		p.OperatingSystem,
+		p.PreInstalled,
+		p.LicenseModel,
+		p.CacheEngine,
+		p.DatabaseEngine,
+		p.DatabaseEdition,
+	)
+}
@@ -0,0 +1,193 @@
+package hermes
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestKey(t *testing.T) {
+	price := func(os, pre, license, engine, edition string) pricing.Price {
+		return pricing.Price{
+			Region:          "ap-northeast-1",
+			UsageType:       "APN1-BoxUsage:m5.large",
+			OperatingSystem: os,
+			PreInstalled:    pre,
+			LicenseModel:    license,
+			DatabaseEngine:  engine,
+			DatabaseEdition: edition,
+		}
+	}
+
+	plist := []pricing.Price{
+		price("Windows", "NA", "License included", "", ""),
+		price("Windows", "NA", pricing.LicenseModelBYOL, "", ""),
+		price("Windows", "SQL Std", "License included", "", ""),
+		price("Linux", "SQL Std", "License included", "", ""),
+		price("NA", "NA", "No License required", "", ""),
+		price("", "", "License included", "Oracle", "Standard Two"),
+		price("", "", pricing.LicenseModelBYOL, "Oracle", "Standard Two"),
+		price("", "", "License included", "SQL Server", "Web"),
+		price("", "", "No license required", "Aurora MySQL", ""),
+	}
+
+	cases := []struct {
+		Quantity usage.Quantity
+		Price    pricing.Price
+	}{
+		{usage.Quantity{Platform: "Windows"}, plist[0]},
+		{usage.Quantity{Platform: "Windows (BYOL)"}, plist[1]},
+		{usage.Quantity{Platform: "Windows with SQL Standard"}, plist[2]},
+		{usage.Quantity{Platform: "Linux with SQL Standard"}, plist[3]},
+		{usage.Quantity{Platform: "NoOperatingSystem"}, plist[4]},
+		{usage.Quantity{DatabaseEngine: "Oracle (SE2)"}, plist[5]},
+		{usage.Quantity{DatabaseEngine: "SQL Server (Web)"}, plist[7]},
+		{usage.Quantity{DatabaseEngine: "Aurora MySQL"}, plist[8]},
+	}
+
+	for _, c := range cases {
+		q := c.Quantity
+		q.Region, q.UsageType = "ap-northeast-1", "APN1-BoxUsage:m5.large"
+
+		key := pricing.TupleKey(
+			c.Price.UsageType,
+			c.Price.OperatingSystem,
+			c.Price.PreInstalled,
+			c.Price.LicenseModel,
+			c.Price.CacheEngine,
+			c.Price.DatabaseEngine,
+			c.Price.DatabaseEdition,
+		)
+		if Key(q) != key {
+			t.Errorf("%v: expected: %v, actual: %v", c.Quantity, key, Key(q))
+		}
+
+		for _, p := range plist {
+			if Match(q, p) != (p == c.Price) {
+				t.Errorf("%v: match %v", c.Quantity, p)
+			}
+		}
+	}
+}
+
+func TestReadMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "mapping.json")
+	json := `{"database_engine": {"Oracle (SE2 BYOL)": {"database_engine": "Oracle", "database_edition": "Standard Two", "license_model": "Bring your own license"}}}`
+	if err := ioutil.WriteFile(file, []byte(json), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	m, err := ReadMapping(file)
+	if err != nil {
+		t.Fatalf("read mapping: %v", err)
+	}
+
+	q := usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-InstanceUsage:db.r5.large", DatabaseEngine: "Oracle (SE2 BYOL)"}
+	p := pricing.Price{Region: "ap-northeast-1", UsageType: "APN1-InstanceUsage:db.r5.large", DatabaseEngine: "Oracle", DatabaseEdition: "Standard Two", LicenseModel: pricing.LicenseModelBYOL}
+	if !m.Match(q, p) {
+		t.Errorf("not matched: %v", m.Attribute(q))
+	}
+
+	if Match(q, p) {
+		t.Errorf("built-in mapping changed: %v", Attribute(q))
+	}
+
+	xlarge := p
+	xlarge.UsageType = "APN1-InstanceUsage:db.r5.xlarge"
+	if m.Match(q, xlarge) {
+		t.Errorf("matched other size: %v", xlarge)
+	}
+
+	if !m.MatchPlatform(q, xlarge) {
+		t.Errorf("platform not matched: %v", xlarge)
+	}
+
+	if _, ok := DatabaseEngines["Oracle (SE2 BYOL)"]; ok {
+		t.Errorf("built-in mapping changed")
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte(`{"platform": {"Linux/UNIX": {"pre_installed": "NA"}}}`), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if _, err := ReadMapping(invalid); err == nil {
+		t.Errorf("expected error")
+	}
+}
+
+// ec2Offer is an offer file of AmazonEC2 in ap-northeast-1 with the attributes of the price list.
+var ec2Offer = `{
+  "offerCode": "AmazonEC2",
+  "version": "20190730012138",
+  "products": {
+    "SKU0000000000021": {"sku": "SKU0000000000021", "productFamily": "Compute Instance", "attributes": {"servicecode": "AmazonEC2", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "m5.large", "tenancy": "Shared", "operatingSystem": "Linux", "licenseModel": "No License required", "usagetype": "APN1-BoxUsage:m5.large", "operation": "RunInstances", "capacitystatus": "Used", "normalizationSizeFactor": "4", "preInstalledSw": "NA"}},
+    "SKU0000000000022": {"sku": "SKU0000000000022", "productFamily": "Compute Instance", "attributes": {"servicecode": "AmazonEC2", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "m5.large", "tenancy": "Shared", "operatingSystem": "Windows", "licenseModel": "No License required", "usagetype": "APN1-BoxUsage:m5.large", "operation": "RunInstances:0006", "capacitystatus": "Used", "normalizationSizeFactor": "4", "preInstalledSw": "SQL Std"}},
+    "SKU0000000000023": {"sku": "SKU0000000000023", "productFamily": "Compute Instance", "attributes": {"servicecode": "AmazonEC2", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "m5.large", "tenancy": "Shared", "operatingSystem": "Windows", "licenseModel": "Bring your own license", "usagetype": "APN1-BoxUsage:m5.large", "operation": "RunInstances:0800", "capacitystatus": "Used", "normalizationSizeFactor": "4", "preInstalledSw": "NA"}}
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU0000000000021": {"SKU0000000000021.JRTCKXETXF": {"sku": "SKU0000000000021", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.124"}}}}},
+      "SKU0000000000022": {"SKU0000000000022.JRTCKXETXF": {"sku": "SKU0000000000022", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.616"}}}}},
+      "SKU0000000000023": {"SKU0000000000023.JRTCKXETXF": {"sku": "SKU0000000000023", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.124"}}}}}
+    },
+    "Reserved": {
+      "SKU0000000000021": {"SKU0000000000021.6QCMYABX3D": {"sku": "SKU0000000000021", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "640"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000022": {"SKU0000000000022.6QCMYABX3D": {"sku": "SKU0000000000022", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "4580"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000023": {"SKU0000000000023.6QCMYABX3D": {"sku": "SKU0000000000023", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "640"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}}
+    }
+  }
+}`
+
+func TestAttributeEC2(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "ec2.json")
+	if err := ioutil.WriteFile(file, []byte(ec2Offer), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	price, err := pricing.Import(file, "AmazonEC2", "ap-northeast-1")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	cases := []struct {
+		Platform string
+		SKU      string
+	}{
+		{"Linux/UNIX", "SKU0000000000021"},
+		{"Windows with SQL Standard", "SKU0000000000022"},
+		{"Windows (BYOL)", "SKU0000000000023"},
+	}
+
+	for _, c := range cases {
+		p := price[c.SKU+".6QCMYABX3D"]
+		q := usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:m5.large", Platform: c.Platform}
+
+		a := Attribute(q)
+		if a.OperatingSystem != p.OperatingSystem || a.PreInstalled != p.PreInstalled || a.LicenseModel != p.LicenseModel {
+			t.Errorf("%s: attribute: %v, price: %v", c.Platform, a, p)
+		}
+
+		for _, pp := range price {
+			if Match(q, pp) != (pp.SKU == c.SKU) {
+				t.Errorf("%s: match %v", c.Platform, pp)
+			}
+		}
+	}
+}
@@ -1,105 +1,145 @@
 package hermes
 
 import (
-	"fmt"
-	"strconv"
-	"strings"
+	"time"
 
 	"github.com/itsubaki/hermes/pkg/pricing"
 	"github.com/itsubaki/hermes/pkg/usage"
 )
 
-func Normalize(quantity []usage.Quantity, mini map[string]pricing.Tuple) []usage.Quantity {
-	n := make([]usage.Quantity, 0)
-	for i := range quantity {
-		hash := fmt.Sprintf(
-			"%s%s%s%s",
-			quantity[i].UsageType,
-			OperatingSystem[quantity[i].Platform],
-			quantity[i].CacheEngine,
-			quantity[i].DatabaseEngine,
-		)
-
-		v, ok := mini[hash]
+// Status of a quantity in Normalize.
+const (
+	Normalized    = "normalized"      // converted to the smallest size of its family
+	NotFlexible   = "not_flexible"    // the price has no instance size flexibility. see Result.Reason.
+	PriceNotFound = "price_not_found" // no price for the usage type, platform and engine
+	NoSizeFactor  = "no_size_factor"  // the price has no normalization size factor
+	InvalidPrice  = "invalid_price"   // the price has an invalid normalization size factor
+)
+
+// Result is a quantity of Normalize and how it was normalized.
+type Result struct {
+	Status   string         `json:"status"`
+	Reason   string         `json:"reason,omitempty"`
+	Quantity usage.Quantity `json:"quantity"`
+	Output   usage.Quantity `json:"output"`
+}
+
+// Normalize converts quantity to the smallest instance size of its family in mini.
+// Multi-AZ database usage is converted to the smallest Single-AZ size with twice the units.
+// Quantities whose price has no instance size flexibility or an invalid normalization size factor
+// are not converted. The prices with an invalid factor are returned in a *pricing.InvalidError.
+func Normalize(quantity []usage.Quantity, mini map[string]pricing.Tuple) ([]usage.Quantity, error) {
+	result, err := NormalizeWithResult(quantity, mini)
+
+	out := make([]usage.Quantity, 0)
+	for _, r := range result {
+		out = append(out, r.Output)
+	}
+
+	return out, err
+}
+
+// NormalizeWithResult returns the Result of each quantity of Normalize.
+func NormalizeWithResult(quantity []usage.Quantity, mini map[string]pricing.Tuple) ([]Result, error) {
+	return NormalizeWithInput(&NormalizeInput{
+		Quantity: quantity,
+		Minimum:  mini,
+	})
+}
+
+type NormalizeInput struct {
+	Quantity []usage.Quantity
+	Minimum  map[string]pricing.Tuple
+	Rules    Rules     // DefaultRules when empty
+	Date     time.Time // the purchase date the rules in effect on. today when zero
+	Mapping  Mapping   // added to the built-in usage to pricing attribute mapping
+}
+
+// NormalizeWithInput returns the Result of each quantity of Normalize
+// with the size flexibility rules in effect on in.Date.
+func NormalizeWithInput(in *NormalizeInput) ([]Result, error) {
+	rules := in.Rules
+	if len(rules) < 1 {
+		rules = DefaultRules
+	}
+
+	date := in.Date
+	if date.IsZero() {
+		date, _ = ParseDate("")
+	}
+
+	quantity, mini := in.Quantity, in.Minimum
+	invalid := make([]pricing.PriceError, 0)
+	seen := make(map[string]bool)
+	report := func(p pricing.Price, err error) {
+		e := pricing.NewPriceError(p, err)
+		if !seen[e.Error()] {
+			seen[e.Error()] = true
+			invalid = append(invalid, e)
+		}
+	}
+
+	out := make([]Result, 0)
+	for _, q := range quantity {
+		v, ok := mini[in.Mapping.Key(q)]
 		if !ok {
-			n = append(n, quantity[i])
+			out = append(out, Result{Status: PriceNotFound, Quantity: q, Output: q})
 			continue
 		}
 
-		if len(v.Minimum.NormalizationSizeFactor) < 1 {
-			n = append(n, quantity[i])
+		if reason := rules.Inflexibility(v.Price, date); len(reason) > 0 {
+			out = append(out, Result{Status: NotFlexible, Reason: reason, Quantity: q, Output: q})
 			continue
 		}
 
-		s0, err := strconv.ParseFloat(v.Minimum.NormalizationSizeFactor, 64)
+		minimum, deployment := v.Minimum, 1.0
+		if len(v.Price.DatabaseEngine) > 0 {
+			minimum, deployment = databaseMinimum(v, mini)
+		}
+
+		s0, ok0, err := pricing.SizeFactor(minimum)
 		if err != nil {
-			panic(fmt.Sprintf("invalid normalization size factor: %v", err))
+			report(minimum, err)
+			out = append(out, Result{Status: InvalidPrice, Reason: minimum.SKU, Quantity: q, Output: q})
+			continue
 		}
 
-		s1, err := strconv.ParseFloat(v.Price.NormalizationSizeFactor, 64)
+		s1, ok1, err := pricing.SizeFactor(v.Price)
 		if err != nil {
-			panic(fmt.Sprintf("invalid normalization size factor: %v", err))
+			report(v.Price, err)
+			out = append(out, Result{Status: InvalidPrice, Reason: v.Price.SKU, Quantity: q, Output: q})
+			continue
 		}
 
-		scale := s1 / s0
-
-		n = append(n, usage.Quantity{
-			AccountID:      quantity[i].AccountID,
-			Description:    quantity[i].Description,
-			Region:         quantity[i].Region,
-			UsageType:      v.Minimum.UsageType,
-			Platform:       quantity[i].Platform,
-			CacheEngine:    quantity[i].CacheEngine,
-			DatabaseEngine: quantity[i].DatabaseEngine,
-			Date:           quantity[i].Date,
-			InstanceHour:   quantity[i].InstanceHour * scale,
-			InstanceNum:    quantity[i].InstanceNum * scale,
-		})
-	}
+		if !ok0 || !ok1 {
+			out = append(out, Result{Status: NoSizeFactor, Quantity: q, Output: q})
+			continue
+		}
 
-	return n
-}
+		scale := s1 * deployment / s0
 
-// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/apply_ri.html
-// Instance size flexibility does not apply to Reserved Instances
-// that are purchased for a specific Availability Zone,
-// bare metal instances,
-// Reserved Instances with dedicated tenancy,
-// and Reserved Instances for Windows,
-// Windows with SQL Standard,
-// Windows with SQL Server Enterprise,
-// Windows with SQL Server Web,
-// RHEL, and SLES.
-// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/apply_ri.html
-// Instance size flexibility does not apply to Reserved Instances
-// that are purchased for a specific Availability Zone,
-// bare metal instances,
-// Reserved Instances with dedicated tenancy,
-// and Reserved Instances for Windows,
-// Windows with SQL Standard,
-// Windows with SQL Server Enterprise,
-// Windows with SQL Server Web,
-// RHEL, and SLES.
-func HasFlexibility(p pricing.Price) bool {
-	if strings.Contains(p.OperatingSystem, "Windows") {
-		return false
-	}
+		n := q
+		n.UsageType = minimum.UsageType
+		n.InstanceHour = q.InstanceHour * scale
+		n.InstanceNum = q.InstanceNum * scale
 
-	if strings.Contains(p.OperatingSystem, "Red Hat Enterprise Linux") {
-		return false
+		out = append(out, Result{Status: Normalized, Quantity: q, Output: n})
 	}
 
-	if strings.Contains(p.OperatingSystem, "SUSE Linux") {
-		return false
+	if len(invalid) > 0 {
+		return out, &pricing.InvalidError{Invalid: invalid}
 	}
 
-	if strings.Contains(p.Tenancy, "dedicated") {
-		return false
-	}
+	return out, nil
+}
 
-	if strings.Contains(p.InstanceType, "cache") {
-		return false
-	}
+// HasFlexibility returns true when p has instance size flexibility today by DefaultRules.
+func HasFlexibility(p pricing.Price) bool {
+	return len(Inflexibility(p)) < 1
+}
 
-	return true
+// Inflexibility returns the reason p has no instance size flexibility today by DefaultRules, or empty when it has.
+func Inflexibility(p pricing.Price) string {
+	today, _ := ParseDate("")
+	return DefaultRules.Inflexibility(p, today)
 }
@@ -2,6 +2,8 @@ package hermes
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/itsubaki/hermes/pkg/pricing"
@@ -14,8 +16,15 @@ func TestNormalize(t *testing.T) {
 		t.Errorf("desirialize pricing: %v", err)
 	}
 
-	family := pricing.Family(plist)
-	mini := pricing.Minimum(family, plist)
+	family, err := pricing.Family(plist)
+	if err != nil {
+		t.Errorf("family: %v", err)
+	}
+
+	mini, err := pricing.Minimum(family, plist)
+	if err != nil {
+		t.Errorf("minimum: %v", err)
+	}
 
 	forecast := []usage.Quantity{
 		{
@@ -48,8 +57,221 @@ func TestNormalize(t *testing.T) {
 		},
 	}
 
-	n := Normalize(forecast, mini)
+	n, err := Normalize(forecast, mini)
+	if err != nil {
+		t.Errorf("normalize: %v", err)
+	}
+
 	for _, nn := range n {
 		fmt.Println(nn)
 	}
 }
+
+func TestNormalizeInvalid(t *testing.T) {
+	mini := map[string]pricing.Tuple{
+		"APN1-BoxUsage:m4.xlargeLinuxNA": {
+			Price:   pricing.Price{SKU: "SKU2", UsageType: "APN1-BoxUsage:m4.xlarge", NormalizationSizeFactor: "8"},
+			Minimum: pricing.Price{SKU: "SKU1", UsageType: "APN1-BoxUsage:m4.large", NormalizationSizeFactor: "4"},
+		},
+		"APN1-BoxUsage:m4.2xlargeLinuxNA": {
+			Price:   pricing.Price{SKU: "SKU3", UsageType: "APN1-BoxUsage:m4.2xlarge", NormalizationSizeFactor: "sixteen"},
+			Minimum: pricing.Price{SKU: "SKU1", UsageType: "APN1-BoxUsage:m4.large", NormalizationSizeFactor: "4"},
+		},
+	}
+
+	forecast := []usage.Quantity{
+		{UsageType: "APN1-BoxUsage:m4.xlarge", Platform: "Linux/UNIX", InstanceNum: 2},
+		{UsageType: "APN1-BoxUsage:m4.2xlarge", Platform: "Linux/UNIX", InstanceNum: 4},
+		{UsageType: "APN1-BoxUsage:m4.2xlarge", Platform: "Linux/UNIX", InstanceNum: 1},
+	}
+
+	n, err := Normalize(forecast, mini)
+	ierr, ok := err.(*pricing.InvalidError)
+	if !ok {
+		t.Fatalf("expected invalid error: %v", err)
+	}
+
+	if len(ierr.Invalid) != 1 || ierr.Invalid[0].SKU != "SKU3" {
+		t.Errorf("invalid: %v", ierr.Invalid)
+	}
+
+	if len(n) != 3 || n[0].UsageType != "APN1-BoxUsage:m4.large" || n[0].InstanceNum != 4 || n[1].UsageType != "APN1-BoxUsage:m4.2xlarge" {
+		t.Errorf("normalized: %v", n)
+	}
+}
+
+func TestNormalizeWithResult(t *testing.T) {
+	plist := []pricing.Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.large", InstanceType: "c5.large", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Shared", NormalizationSizeFactor: "4"},
+		{SKU: "SKU2", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.xlarge", InstanceType: "c5.xlarge", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Shared", NormalizationSizeFactor: "8"},
+		{SKU: "SKU3", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.metal", InstanceType: "c5.metal", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Shared", NormalizationSizeFactor: "192"},
+		{SKU: "SKU4", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.large", InstanceType: "c5.large", OperatingSystem: "Windows", PreInstalled: "NA", Tenancy: "Shared", NormalizationSizeFactor: "4"},
+		{SKU: "SKU5", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.xlarge", InstanceType: "c5.xlarge", OperatingSystem: "Windows", PreInstalled: "NA", Tenancy: "Shared", NormalizationSizeFactor: "8"},
+		{SKU: "SKU6", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.large", InstanceType: "c5.large", OperatingSystem: "Linux", PreInstalled: "SQL Std", Tenancy: "Shared", NormalizationSizeFactor: "4"},
+		{SKU: "SKU7", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.xlarge", InstanceType: "c5.xlarge", OperatingSystem: "Linux", PreInstalled: "SQL Std", Tenancy: "Shared", NormalizationSizeFactor: "8"},
+		{SKU: "SKU8", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-DedicatedUsage:c5.xlarge", InstanceType: "c5.xlarge", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Dedicated", NormalizationSizeFactor: "8"},
+	}
+
+	family, err := pricing.Family(plist)
+	if err != nil {
+		t.Fatalf("family: %v", err)
+	}
+
+	mini, err := pricing.Minimum(family, plist)
+	if err != nil {
+		t.Fatalf("minimum: %v", err)
+	}
+
+	cases := []struct {
+		Quantity  usage.Quantity
+		Status    string
+		Reason    string
+		UsageType string
+		Num       float64
+	}{
+		{usage.Quantity{UsageType: "APN1-BoxUsage:c5.xlarge", Platform: "Linux/UNIX", Tenancy: "Shared", InstanceNum: 2}, Normalized, "", "APN1-BoxUsage:c5.large", 4},
+		{usage.Quantity{UsageType: "APN1-BoxUsage:c5.metal", Platform: "Linux/UNIX", InstanceNum: 1}, NotFlexible, "metal", "APN1-BoxUsage:c5.metal", 1},
+		{usage.Quantity{UsageType: "APN1-BoxUsage:c5.xlarge", Platform: "Windows", InstanceNum: 1}, NotFlexible, "operating_system", "APN1-BoxUsage:c5.xlarge", 1},
+		{usage.Quantity{UsageType: "APN1-BoxUsage:c5.xlarge", Platform: "Linux with SQL Standard", InstanceNum: 1}, NotFlexible, "pre_installed", "APN1-BoxUsage:c5.xlarge", 1},
+		{usage.Quantity{UsageType: "APN1-DedicatedUsage:c5.xlarge", Platform: "Linux/UNIX", InstanceNum: 1}, NotFlexible, "tenancy", "APN1-DedicatedUsage:c5.xlarge", 1},
+		{usage.Quantity{UsageType: "APN1-BoxUsage:c5.xlarge", Platform: "SUSE Linux", InstanceNum: 1}, PriceNotFound, "", "APN1-BoxUsage:c5.xlarge", 1},
+	}
+
+	q := make([]usage.Quantity, 0)
+	for _, c := range cases {
+		q = append(q, c.Quantity)
+	}
+
+	result, err := NormalizeWithResult(q, mini)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+
+	for i, c := range cases {
+		r := result[i]
+		if r.Status != c.Status || r.Reason != c.Reason || r.Output.UsageType != c.UsageType || r.Output.InstanceNum != c.Num {
+			t.Errorf("expected: %v, actual: %v", c, r)
+		}
+	}
+
+	if result[0].Output.Tenancy != "Shared" {
+		t.Errorf("tenancy: %v", result[0].Output)
+	}
+}
+
+// rdsOffer is an offer file of AmazonRDS in ap-northeast-1.
+// The normalizationSizeFactor of a Multi-AZ product is the one of the Single-AZ product of the same size.
+var rdsOffer = `{
+  "offerCode": "AmazonRDS",
+  "version": "20190730012138",
+  "products": {
+    "SKU0000000000011": {"sku": "SKU0000000000011", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.large", "instanceFamily": "Memory optimized", "vcpu": "2", "memory": "16 GiB", "currentGeneration": "Yes", "databaseEngine": "MySQL", "licenseModel": "No license required", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.large", "operation": "CreateDBInstance:0002", "normalizationSizeFactor": "4"}},
+    "SKU0000000000012": {"sku": "SKU0000000000012", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.xlarge", "instanceFamily": "Memory optimized", "vcpu": "4", "memory": "32 GiB", "currentGeneration": "Yes", "databaseEngine": "MySQL", "licenseModel": "No license required", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.xlarge", "operation": "CreateDBInstance:0002", "normalizationSizeFactor": "8"}},
+    "SKU0000000000013": {"sku": "SKU0000000000013", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.large", "instanceFamily": "Memory optimized", "vcpu": "2", "memory": "16 GiB", "currentGeneration": "Yes", "databaseEngine": "MySQL", "licenseModel": "No license required", "deploymentOption": "Multi-AZ", "usagetype": "APN1-Multi-AZUsage:db.r5.large", "operation": "CreateDBInstance:0002", "normalizationSizeFactor": "4"}},
+    "SKU0000000000014": {"sku": "SKU0000000000014", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.xlarge", "instanceFamily": "Memory optimized", "vcpu": "4", "memory": "32 GiB", "currentGeneration": "Yes", "databaseEngine": "MySQL", "licenseModel": "No license required", "deploymentOption": "Multi-AZ", "usagetype": "APN1-Multi-AZUsage:db.r5.xlarge", "operation": "CreateDBInstance:0002", "normalizationSizeFactor": "8"}},
+    "SKU0000000000015": {"sku": "SKU0000000000015", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.large", "instanceFamily": "Memory optimized", "vcpu": "2", "memory": "16 GiB", "currentGeneration": "Yes", "databaseEngine": "SQL Server", "databaseEdition": "Standard", "licenseModel": "License included", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.large", "operation": "CreateDBInstance:0012", "normalizationSizeFactor": "4"}},
+    "SKU0000000000016": {"sku": "SKU0000000000016", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.xlarge", "instanceFamily": "Memory optimized", "vcpu": "4", "memory": "32 GiB", "currentGeneration": "Yes", "databaseEngine": "SQL Server", "databaseEdition": "Standard", "licenseModel": "License included", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.xlarge", "operation": "CreateDBInstance:0012", "normalizationSizeFactor": "8"}},
+    "SKU0000000000017": {"sku": "SKU0000000000017", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.large", "instanceFamily": "Memory optimized", "vcpu": "2", "memory": "16 GiB", "currentGeneration": "Yes", "databaseEngine": "Oracle", "databaseEdition": "Standard Two", "licenseModel": "License included", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.large", "operation": "CreateDBInstance:0005", "normalizationSizeFactor": "4"}},
+    "SKU0000000000018": {"sku": "SKU0000000000018", "productFamily": "Database Instance", "attributes": {"servicecode": "AmazonRDS", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r5.xlarge", "instanceFamily": "Memory optimized", "vcpu": "4", "memory": "32 GiB", "currentGeneration": "Yes", "databaseEngine": "Oracle", "databaseEdition": "Standard Two", "licenseModel": "License included", "deploymentOption": "Single-AZ", "usagetype": "APN1-InstanceUsage:db.r5.xlarge", "operation": "CreateDBInstance:0005", "normalizationSizeFactor": "8"}}
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU0000000000011": {"SKU0000000000011.JRTCKXETXF": {"sku": "SKU0000000000011", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.25"}}}}},
+      "SKU0000000000012": {"SKU0000000000012.JRTCKXETXF": {"sku": "SKU0000000000012", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.5"}}}}},
+      "SKU0000000000013": {"SKU0000000000013.JRTCKXETXF": {"sku": "SKU0000000000013", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.5"}}}}},
+      "SKU0000000000014": {"SKU0000000000014.JRTCKXETXF": {"sku": "SKU0000000000014", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "1"}}}}},
+      "SKU0000000000015": {"SKU0000000000015.JRTCKXETXF": {"sku": "SKU0000000000015", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "1.24"}}}}},
+      "SKU0000000000016": {"SKU0000000000016.JRTCKXETXF": {"sku": "SKU0000000000016", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "2.48"}}}}},
+      "SKU0000000000017": {"SKU0000000000017.JRTCKXETXF": {"sku": "SKU0000000000017", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.57"}}}}},
+      "SKU0000000000018": {"SKU0000000000018.JRTCKXETXF": {"sku": "SKU0000000000018", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "1.14"}}}}}
+    },
+    "Reserved": {
+      "SKU0000000000011": {"SKU0000000000011.6QCMYABX3D": {"sku": "SKU0000000000011", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "1346"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000012": {"SKU0000000000012.6QCMYABX3D": {"sku": "SKU0000000000012", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "2692"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000013": {"SKU0000000000013.6QCMYABX3D": {"sku": "SKU0000000000013", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "2692"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000014": {"SKU0000000000014.6QCMYABX3D": {"sku": "SKU0000000000014", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "5384"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000015": {"SKU0000000000015.6QCMYABX3D": {"sku": "SKU0000000000015", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "6600"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000016": {"SKU0000000000016.6QCMYABX3D": {"sku": "SKU0000000000016", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "13200"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000017": {"SKU0000000000017.6QCMYABX3D": {"sku": "SKU0000000000017", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "3000"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}},
+      "SKU0000000000018": {"SKU0000000000018.6QCMYABX3D": {"sku": "SKU0000000000018", "offerTermCode": "6QCMYABX3D", "priceDimensions": {"q": {"unit": "Quantity", "pricePerUnit": {"USD": "6000"}}, "h": {"unit": "Hrs", "pricePerUnit": {"USD": "0"}}}, "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}}}
+    }
+  }
+}`
+
+func TestNormalizeDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := fmt.Sprintf("%s/index.json", dir)
+	if err := ioutil.WriteFile(file, []byte(rdsOffer), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	imported, err := pricing.Import(file, "AmazonRDS", "ap-northeast-1")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	plist := make([]pricing.Price, 0)
+	for _, p := range imported {
+		plist = append(plist, p)
+	}
+
+	family, err := pricing.Family(plist)
+	if err != nil {
+		t.Fatalf("family: %v", err)
+	}
+
+	mini, err := pricing.Minimum(family, plist)
+	if err != nil {
+		t.Fatalf("minimum: %v", err)
+	}
+
+	cases := []struct {
+		Quantity  usage.Quantity
+		Status    string
+		Reason    string
+		UsageType string
+		Num       float64
+	}{
+		{usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-InstanceUsage:db.r5.xlarge", DatabaseEngine: "MySQL", InstanceNum: 1}, Normalized, "", "APN1-InstanceUsage:db.r5.large", 2},
+		{usage.Quantity{UsageType: "APN1-Multi-AZUsage:db.r5.xlarge", DatabaseEngine: "MySQL", InstanceNum: 1}, Normalized, "", "APN1-InstanceUsage:db.r5.large", 4},
+		{usage.Quantity{UsageType: "APN1-Multi-AZUsage:db.r5.large", DatabaseEngine: "MySQL", InstanceNum: 3}, Normalized, "", "APN1-InstanceUsage:db.r5.large", 6},
+		{usage.Quantity{UsageType: "APN1-InstanceUsage:db.r5.xlarge", DatabaseEngine: "SQL Server (SE)", InstanceNum: 1}, NotFlexible, "database_engine", "APN1-InstanceUsage:db.r5.xlarge", 1},
+		{usage.Quantity{UsageType: "APN1-InstanceUsage:db.r5.xlarge", DatabaseEngine: "Oracle (SE2)", InstanceNum: 1}, NotFlexible, "license_model", "APN1-InstanceUsage:db.r5.xlarge", 1},
+		{usage.Quantity{UsageType: "APN1-InstanceUsage:db.r5.xlarge", DatabaseEngine: "Oracle", InstanceNum: 1}, PriceNotFound, "", "APN1-InstanceUsage:db.r5.xlarge", 1},
+	}
+
+	q := make([]usage.Quantity, 0)
+	for _, c := range cases {
+		q = append(q, c.Quantity)
+	}
+
+	result, err := NormalizeWithResult(q, mini)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+
+	for i, c := range cases {
+		r := result[i]
+		if r.Status != c.Status || r.Reason != c.Reason || r.Output.UsageType != c.UsageType || r.Output.InstanceNum != c.Num {
+			t.Errorf("expected: %v, actual: %v", c, r)
+		}
+	}
+
+	mapping := Mapping{DatabaseEngine: map[string]DatabaseEngine{
+		"Oracle": {DatabaseEngine: "Oracle", DatabaseEdition: "Standard Two", LicenseModel: LicenseModelIncluded},
+	}}
+
+	mapped, err := NormalizeWithInput(&NormalizeInput{Quantity: q[len(q)-1:], Minimum: mini, Mapping: mapping})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+
+	if mapped[0].Status != NotFlexible || mapped[0].Reason != "license_model" {
+		t.Errorf("mapped: %v", mapped[0])
+	}
+}
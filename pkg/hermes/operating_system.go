@@ -1,22 +1,49 @@
 package hermes
 
+import "github.com/itsubaki/hermes/pkg/pricing"
+
+// Platform is the AWS Pricing attributes of a Usage Platform.
+type Platform struct {
+	OperatingSystem string `json:"operating_system"`
+	PreInstalled    string `json:"pre_installed"`
+	LicenseModel    string `json:"license_model"`
+}
+
+const (
+	LicenseModelNotRequired = "No License required"
+	LicenseModelIncluded    = "License included"
+)
+
 /*
-OperatingSystem returns AWS Pricing OperatingSystem from Usage Platform.
+Platforms returns AWS Pricing OperatingSystem, PreInstalled and LicenseModel from Usage Platform.
+The license model of EC2 prices is "No License required" except BYOL, also for Windows and SQL Server.
 */
-var OperatingSystem = map[string]string{
-	"Amazon Linux":                "Linux",   // PreInstalled: NA
-	"Linux/UNIX":                  "Linux",   // PreInstalled: NA
-	"Linux/UNIX (Amazon VPC)":     "Linux",   // PreInstalled: NA
-	"Linux with SQL Standard":     "Linux",   // PreInstalled: SQL Standard
-	"Linux with SQL Web":          "Linux",   // PreInstalled: SQL Web
-	"Linux with SQL Enterprise":   "Linux",   // PreInstalled: SQL Enterprise
-	"Red Hat Enterprise Linux":    "RHEL",    // PreInstalled: NA
-	"SUSE Linux":                  "SUSE",    // PreInstalled: NA
-	"Windows":                     "Windows", // PreInstalled: NA
-	"Windows (Amazon VPC)":        "Windows", // PreInstalled: NA
-	"Windows with SQL Standard":   "Windows", // PreInstalled: SQL Standard
-	"Windows with SQL Web":        "Windows", // PreInstalled: SQL Web
-	"Windows with SQL Enterprise": "Windows", // PreInstalled: SQL Enterprise
-	"Windows (BYOL)":              "",        // pricing not found
-	"NoOperatingSystem":           "",        // pricing not found
+var Platforms = map[string]Platform{
+	"Amazon Linux":                {"Linux", "NA", LicenseModelNotRequired},
+	"Linux/UNIX":                  {"Linux", "NA", LicenseModelNotRequired},
+	"Linux/UNIX (Amazon VPC)":     {"Linux", "NA", LicenseModelNotRequired},
+	"Linux with SQL Standard":     {"Linux", "SQL Std", LicenseModelNotRequired},
+	"Linux with SQL Web":          {"Linux", "SQL Web", LicenseModelNotRequired},
+	"Linux with SQL Enterprise":   {"Linux", "SQL Ent", LicenseModelNotRequired},
+	"Red Hat Enterprise Linux":    {"RHEL", "NA", LicenseModelNotRequired},
+	"SUSE Linux":                  {"SUSE", "NA", LicenseModelNotRequired},
+	"Windows":                     {"Windows", "NA", LicenseModelNotRequired},
+	"Windows (Amazon VPC)":        {"Windows", "NA", LicenseModelNotRequired},
+	"Windows with SQL Standard":   {"Windows", "SQL Std", LicenseModelNotRequired},
+	"Windows with SQL Web":        {"Windows", "SQL Web", LicenseModelNotRequired},
+	"Windows with SQL Enterprise": {"Windows", "SQL Ent", LicenseModelNotRequired},
+	"Windows (BYOL)":              {"Windows", "NA", pricing.LicenseModelBYOL},
+	"NoOperatingSystem":           {"NA", "NA", LicenseModelNotRequired}, // dedicated hosts
 }
+
+// OperatingSystem returns AWS Pricing OperatingSystem from Usage Platform.
+//
+// Deprecated: Use Platforms, which also has PreInstalled and LicenseModel.
+var OperatingSystem = func() map[string]string {
+	out := make(map[string]string)
+	for k, v := range Platforms {
+		out[k] = v.OperatingSystem
+	}
+
+	return out
+}()
@@ -0,0 +1,169 @@
+package hermes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+)
+
+const (
+	EC2         = "AmazonEC2"
+	RDS         = "AmazonRDS"
+	ElastiCache = "AmazonElastiCache"
+	Redshift    = "AmazonRedshift"
+	OpenSearch  = "AmazonES"
+	DynamoDB    = "AmazonDynamoDB"
+	MemoryDB    = "AmazonMemoryDB"
+)
+
+// Rule is an instance size flexibility rule in effect from EffectiveDate (YYYY-MM-DD).
+// Engine, Tenancy, InstanceType, LicenseModel and PreInstalled are path.Match patterns.
+// Engine matches the operating system, cache engine or database engine of a price.
+// Empty fields match any price.
+type Rule struct {
+	Service       string `json:"service"`
+	Engine        string `json:"engine,omitempty"`
+	Tenancy       string `json:"tenancy,omitempty"`
+	InstanceType  string `json:"instance_type,omitempty"`
+	LicenseModel  string `json:"license_model,omitempty"`
+	PreInstalled  string `json:"pre_installed,omitempty"`
+	Flexible      bool   `json:"flexible"`
+	Reason        string `json:"reason,omitempty"`
+	EffectiveDate string `json:"effective_date"`
+}
+
+// Rules decides the size flexibility of a price on a date.
+// Among the rules in effect and matching a price, the one with the latest EffectiveDate applies,
+// and the latter of the ones with the same date. A price no rule matches is flexible.
+type Rules []Rule
+
+// DefaultRules are the rules built into hermes.
+// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/apply_ri.html
+// https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_WorkingWithReservedDBInstances.html
+// https://docs.aws.amazon.com/AmazonElastiCache/latest/dg/CacheNodes.Reserved.html
+var DefaultRules = Rules{
+	{Service: EC2, Engine: "Windows*", Reason: "operating_system", EffectiveDate: "2017-03-01"},
+	{Service: EC2, Engine: "RHEL*", Reason: "operating_system", EffectiveDate: "2017-03-01"},
+	{Service: EC2, Engine: "Red Hat Enterprise Linux*", Reason: "operating_system", EffectiveDate: "2017-03-01"},
+	{Service: EC2, Engine: "SUSE*", Reason: "operating_system", EffectiveDate: "2017-03-01"},
+	{Service: EC2, PreInstalled: "SQL*", Reason: "pre_installed", EffectiveDate: "2017-03-01"},
+	{Service: EC2, Tenancy: "Dedicated", Reason: "tenancy", EffectiveDate: "2017-03-01"},
+	{Service: EC2, Tenancy: "Host", Reason: "tenancy", EffectiveDate: "2017-03-01"},
+	{Service: EC2, InstanceType: "*.metal*", Reason: "metal", EffectiveDate: "2017-03-01"},
+	{Service: RDS, Engine: "SQL Server*", Reason: "database_engine", EffectiveDate: "2017-10-01"},
+	{Service: RDS, Engine: "Oracle*", Reason: "license_model", EffectiveDate: "2017-10-01"},
+	{Service: RDS, Engine: "Oracle*", LicenseModel: pricing.LicenseModelBYOL, Flexible: true, EffectiveDate: "2017-10-01"},
+	{Service: ElastiCache, Reason: "cache", EffectiveDate: "2017-01-01"},
+	{Service: ElastiCache, Flexible: true, EffectiveDate: "2024-10-01"},
+	{Service: Redshift, Reason: "node", EffectiveDate: "2013-01-01"},
+	{Service: OpenSearch, Reason: "node", EffectiveDate: "2015-01-01"},
+	{Service: DynamoDB, Reason: "capacity_unit", EffectiveDate: "2012-01-01"},
+}
+
+// DateLayout is the layout of EffectiveDate and the purchase date.
+const DateLayout = "2006-01-02"
+
+// ParseDate returns the purchase date of s (YYYY-MM-DD), or today when s is empty.
+func ParseDate(s string) (time.Time, error) {
+	if len(s) < 1 {
+		return time.Now().UTC().Truncate(24 * time.Hour), nil
+	}
+
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date %s: %v", s, err)
+	}
+
+	return d, nil
+}
+
+// ReadRules returns the rules in the json file.
+func ReadRules(file string) (Rules, error) {
+	read, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %v", file, err)
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(read, &rules); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %v", file, err)
+	}
+
+	for i, r := range rules {
+		if _, err := time.Parse(DateLayout, r.EffectiveDate); err != nil {
+			return nil, fmt.Errorf("rule %d: effective date: %v", i, err)
+		}
+
+		for _, p := range []string{r.Engine, r.Tenancy, r.InstanceType, r.LicenseModel, r.PreInstalled} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: pattern %q: %v", i, p, err)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// Inflexibility returns the reason p has no size flexibility on date, or empty when it has.
+// A rule with an invalid EffectiveDate is never in effect.
+func (r Rules) Inflexibility(p pricing.Price, date time.Time) string {
+	var rule *Rule
+	var effective time.Time
+	for i := range r {
+		e, err := time.Parse(DateLayout, r[i].EffectiveDate)
+		if err != nil || e.After(date) || !r[i].Match(p) {
+			continue
+		}
+
+		if rule == nil || !e.Before(effective) {
+			rule, effective = &r[i], e
+		}
+	}
+
+	if rule == nil || rule.Flexible {
+		return ""
+	}
+
+	if len(rule.Reason) < 1 {
+		return "rule"
+	}
+
+	return rule.Reason
+}
+
+// Match returns true when p matches all the fields of r.
+func (r Rule) Match(p pricing.Price) bool {
+	if r.Service != Service(p) {
+		return false
+	}
+
+	for _, m := range []struct {
+		Pattern string
+		Value   string
+	}{
+		{r.Engine, p.OperatingSystem + p.CacheEngine + p.DatabaseEngine},
+		{r.Tenancy, p.Tenancy},
+		{r.InstanceType, p.InstanceType},
+		{r.LicenseModel, p.LicenseModel},
+		{r.PreInstalled, p.PreInstalled},
+	} {
+		if len(m.Pattern) < 1 {
+			continue
+		}
+
+		if ok, _ := path.Match(m.Pattern, m.Value); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Service returns the service code of p.
+func Service(p pricing.Price) string {
+	return p.Service()
+}
@@ -0,0 +1,95 @@
+package hermes
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+)
+
+func TestRulesInflexibility(t *testing.T) {
+	cases := []struct {
+		Price  pricing.Price
+		Date   string
+		Reason string
+	}{
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Shared"}, "2019-08-01", ""},
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Windows", PreInstalled: "NA", Tenancy: "Shared"}, "2019-08-01", "operating_system"},
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Windows", PreInstalled: "NA", Tenancy: "Shared"}, "2016-08-01", ""},
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Red Hat Enterprise Linux with HA", PreInstalled: "NA", Tenancy: "Shared"}, "2019-08-01", "operating_system"},
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Linux", PreInstalled: "SQL Web", Tenancy: "Shared"}, "2019-08-01", "pre_installed"},
+		{pricing.Price{InstanceType: "c5.large", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Host"}, "2019-08-01", "tenancy"},
+		{pricing.Price{InstanceType: "c7i.metal-24xl", OperatingSystem: "Linux", PreInstalled: "NA", Tenancy: "Shared"}, "2019-08-01", "metal"},
+		{pricing.Price{InstanceType: "db.r5.large", DatabaseEngine: "Oracle", LicenseModel: "License included"}, "2019-08-01", "license_model"},
+		{pricing.Price{InstanceType: "db.r5.large", DatabaseEngine: "Oracle", LicenseModel: pricing.LicenseModelBYOL}, "2019-08-01", ""},
+		{pricing.Price{InstanceType: "db.r5.large", DatabaseEngine: "SQL Server", LicenseModel: "License included"}, "2019-08-01", "database_engine"},
+		{pricing.Price{InstanceType: "cache.r5.large", CacheEngine: "Redis"}, "2019-08-01", "cache"},
+		{pricing.Price{InstanceType: "cache.r5.large", CacheEngine: "Redis"}, "2024-10-01", ""},
+		{pricing.Price{InstanceType: "dc2.large", UsageType: "APN1-Node:dc2.large"}, "2019-08-01", "node"},
+		{pricing.Price{InstanceType: "r5.large.search", UsageType: "APN1-ESInstance:r5.large.search"}, "2019-08-01", "node"},
+		{pricing.Price{UsageType: "APN1-ReadCapacityUnit-Hrs"}, "2019-08-01", "capacity_unit"},
+		{pricing.Price{InstanceType: "db.r6g.large", UsageType: "APN1-NodeUsage:db.r6g.large", CacheEngine: "Redis"}, "2019-08-01", ""},
+	}
+
+	for _, c := range cases {
+		date, err := ParseDate(c.Date)
+		if err != nil {
+			t.Fatalf("parse date: %v", err)
+		}
+
+		if r := DefaultRules.Inflexibility(c.Price, date); r != c.Reason {
+			t.Errorf("%v %v: expected: %q, actual: %q", c.Price, c.Date, c.Reason, r)
+		}
+	}
+}
+
+func TestReadRules(t *testing.T) {
+	file, err := ioutil.TempFile("", "rules")
+	if err != nil {
+		t.Fatalf("temp file: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	file.WriteString(`[
+	  {"service": "AmazonEC2", "engine": "SUSE*", "reason": "operating_system", "effective_date": "2017-03-01"},
+	  {"service": "AmazonEC2", "engine": "SUSE*", "flexible": true, "effective_date": "2019-01-01"}
+	]`)
+	file.Close()
+
+	rules, err := ReadRules(file.Name())
+	if err != nil {
+		t.Fatalf("read rules: %v", err)
+	}
+
+	suse := pricing.Price{InstanceType: "c5.large", OperatingSystem: "SUSE"}
+	if r := rules.Inflexibility(suse, time.Date(2018, 8, 1, 0, 0, 0, 0, time.UTC)); r != "operating_system" {
+		t.Errorf("reason: %v", r)
+	}
+
+	if r := rules.Inflexibility(suse, time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC)); r != "" {
+		t.Errorf("reason: %v", r)
+	}
+
+	ioutil.WriteFile(file.Name(), []byte(`[{"service": "AmazonEC2", "engine": "[", "effective_date": "2017-03-01"}]`), 0644)
+	if _, err := ReadRules(file.Name()); err == nil {
+		t.Errorf("expected pattern error")
+	}
+
+	ioutil.WriteFile(file.Name(), []byte(`[{"service": "AmazonEC2", "effective_date": "2017/03/01"}]`), 0644)
+	if _, err := ReadRules(file.Name()); err == nil {
+		t.Errorf("expected effective date error")
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	if _, err := ParseDate("2019-8-1"); err == nil {
+		t.Errorf("expected error")
+	}
+
+	d, err := ParseDate("2019-08-01")
+	if err != nil || d != time.Date(2019, 8, 1, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("date: %v, %v", d, err)
+	}
+}
@@ -0,0 +1,153 @@
+package hermes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+// Units is the normalized units per hour of a family in a month.
+// The normalized units of a quantity are its normalization size factor × instance hours / hours in the month.
+type Units struct {
+	AccountID       string             `json:"account_id,omitempty"`
+	Description     string             `json:"description,omitempty"`
+	Region          string             `json:"region"`
+	Family          string             `json:"family"`
+	Platform        string             `json:"platform,omitempty"`
+	CacheEngine     string             `json:"cache_engine,omitempty"`
+	DatabaseEngine  string             `json:"database_engine,omitempty"`
+	Date            string             `json:"date"`
+	InstanceHour    float64            `json:"instance_hour"`
+	NormalizedUnits float64            `json:"normalized_units"`
+	SizeMix         map[string]float64 `json:"size_mix"`        // normalized units of each usage type
+	Total           bool               `json:"total,omitempty"` // the total of all accounts
+}
+
+// SizeMixString returns the size mix of u in ascending order of usage type.
+func (u Units) SizeMixString() string {
+	key := make([]string, 0)
+	for k := range u.SizeMix {
+		key = append(key, k)
+	}
+	sort.Strings(key)
+
+	mix := make([]string, 0)
+	for _, k := range key {
+		mix = append(mix, fmt.Sprintf("%s:%.3f", k, u.SizeMix[k]))
+	}
+
+	return strings.Join(mix, " ")
+}
+
+// NormalizedUnits returns the normalized units of quantity for each account, family and month,
+// followed by the total of all accounts for each family and month (Total), and the quantities skipped because they have no price or normalization size factor in mini.
+// Multi-AZ database usage counts twice in the Single-AZ family.
+// quantity is matched to the prices in mini by m.
+// Prices with an invalid factor are returned in a *pricing.InvalidError.
+func NormalizedUnits(quantity []usage.Quantity, mini map[string]pricing.Tuple, m Mapping) ([]Units, []Result, error) {
+	invalid := make([]pricing.PriceError, 0)
+	seen := make(map[string]bool)
+
+	units, order := make(map[string]Units), make([]string, 0)
+	totals, totalOrder := make(map[string]Units), make([]string, 0)
+	add := func(m map[string]Units, order *[]string, row Units, q usage.Quantity, f float64) {
+		key := fmt.Sprintf(
+			"%s/%s/%s/%s/%s/%s/%s",
+			row.AccountID,
+			row.Region,
+			row.Family,
+			row.Platform,
+			row.CacheEngine,
+			row.DatabaseEngine,
+			row.Date,
+		)
+
+		u, ok := m[key]
+		if !ok {
+			u = row
+			u.SizeMix = make(map[string]float64)
+			*order = append(*order, key)
+		}
+
+		u.InstanceHour = u.InstanceHour + q.InstanceHour
+		u.NormalizedUnits = u.NormalizedUnits + f*q.InstanceNum
+		u.SizeMix[q.UsageType] = u.SizeMix[q.UsageType] + f*q.InstanceNum
+		m[key] = u
+	}
+
+	skipped := make([]Result, 0)
+	for _, q := range quantity {
+		v, ok := mini[m.Key(q)]
+		if !ok {
+			skipped = append(skipped, Result{Status: PriceNotFound, Quantity: q, Output: q})
+			continue
+		}
+
+		f, ok, err := pricing.SizeFactor(v.Price)
+		if err != nil {
+			e := pricing.NewPriceError(v.Price, err)
+			if !seen[e.Error()] {
+				seen[e.Error()] = true
+				invalid = append(invalid, e)
+			}
+
+			skipped = append(skipped, Result{Status: InvalidPrice, Reason: v.Price.SKU, Quantity: q, Output: q})
+			continue
+		}
+
+		if !ok {
+			skipped = append(skipped, Result{Status: NoSizeFactor, Quantity: q, Output: q})
+			continue
+		}
+
+		family := q.UsageType
+		if strings.LastIndex(family, ".") > 0 {
+			family = family[:strings.LastIndex(family, ".")]
+		}
+
+		f = f * DeploymentFactor(family)
+		family = SingleAZ(family)
+
+		row := Units{
+			AccountID:      q.AccountID,
+			Description:    q.Description,
+			Region:         q.Region,
+			Family:         family,
+			Platform:       q.Platform,
+			CacheEngine:    q.CacheEngine,
+			DatabaseEngine: q.DatabaseEngine,
+			Date:           q.Date,
+		}
+		add(units, &order, row, q, f)
+
+		row.AccountID, row.Description, row.Total = "", "", true
+		add(totals, &totalOrder, row, q, f)
+	}
+
+	out := make([]Units, 0)
+	for _, k := range order {
+		out = append(out, units[k])
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Family < out[j].Family })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].AccountID < out[j].AccountID })
+
+	total := make([]Units, 0)
+	for _, k := range totalOrder {
+		total = append(total, totals[k])
+	}
+
+	sort.SliceStable(total, func(i, j int) bool { return total[i].Date < total[j].Date })
+	sort.SliceStable(total, func(i, j int) bool { return total[i].Family < total[j].Family })
+	out = append(out, total...)
+
+	if len(invalid) > 0 {
+		return out, skipped, &pricing.InvalidError{Invalid: invalid}
+	}
+
+	return out, skipped, nil
+}
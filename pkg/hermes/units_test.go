@@ -0,0 +1,63 @@
+package hermes
+
+import (
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestNormalizedUnits(t *testing.T) {
+	mini := map[string]pricing.Tuple{
+		"APN1-BoxUsage:c4.largeLinuxNA":       {Price: pricing.Price{UsageType: "APN1-BoxUsage:c4.large", NormalizationSizeFactor: "4"}},
+		"APN1-BoxUsage:c4.xlargeLinuxNA":      {Price: pricing.Price{UsageType: "APN1-BoxUsage:c4.xlarge", NormalizationSizeFactor: "8"}},
+		"APN1-Multi-AZUsage:db.r5.largeMySQL": {Price: pricing.Price{UsageType: "APN1-Multi-AZUsage:db.r5.large", DatabaseEngine: "MySQL", NormalizationSizeFactor: "4"}},
+		"APN1-InstanceUsage:db.r5.largeMySQL": {Price: pricing.Price{UsageType: "APN1-InstanceUsage:db.r5.large", DatabaseEngine: "MySQL", NormalizationSizeFactor: "4"}},
+	}
+
+	quantity := []usage.Quantity{
+		{AccountID: "111111111111", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", Date: "2019-08", InstanceHour: 744 * 2, InstanceNum: 2},
+		{AccountID: "222222222222", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.xlarge", Platform: "Linux/UNIX", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "222222222222", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.xlarge", Platform: "Linux/UNIX", Date: "2019-09", InstanceHour: 720, InstanceNum: 1},
+		{AccountID: "222222222222", Region: "ap-northeast-1", UsageType: "APN1-Multi-AZUsage:db.r5.large", DatabaseEngine: "MySQL", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "222222222222", Region: "ap-northeast-1", UsageType: "APN1-InstanceUsage:db.r5.large", DatabaseEngine: "MySQL", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "222222222222", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:m4.large", Platform: "Linux/UNIX", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+	}
+
+	units, skipped, err := NormalizedUnits(quantity, mini, Mapping{})
+	if err != nil {
+		t.Fatalf("normalized units: %v", err)
+	}
+
+	if len(skipped) != 1 || skipped[0].Status != PriceNotFound || skipped[0].Quantity.UsageType != "APN1-BoxUsage:m4.large" {
+		t.Errorf("skipped: %v", skipped)
+	}
+
+	expected := []struct {
+		AccountID string
+		Family    string
+		Date      string
+		Units     float64
+		SizeMix   string
+		Total     bool
+	}{
+		{"111111111111", "APN1-BoxUsage:c4", "2019-08", 8, "APN1-BoxUsage:c4.large:8.000", false},
+		{"222222222222", "APN1-BoxUsage:c4", "2019-08", 8, "APN1-BoxUsage:c4.xlarge:8.000", false},
+		{"222222222222", "APN1-BoxUsage:c4", "2019-09", 8, "APN1-BoxUsage:c4.xlarge:8.000", false},
+		{"222222222222", "APN1-InstanceUsage:db.r5", "2019-08", 12, "APN1-InstanceUsage:db.r5.large:4.000 APN1-Multi-AZUsage:db.r5.large:8.000", false},
+		{"", "APN1-BoxUsage:c4", "2019-08", 16, "APN1-BoxUsage:c4.large:8.000 APN1-BoxUsage:c4.xlarge:8.000", true},
+		{"", "APN1-BoxUsage:c4", "2019-09", 8, "APN1-BoxUsage:c4.xlarge:8.000", true},
+		{"", "APN1-InstanceUsage:db.r5", "2019-08", 12, "APN1-InstanceUsage:db.r5.large:4.000 APN1-Multi-AZUsage:db.r5.large:8.000", true},
+	}
+
+	if len(units) != len(expected) {
+		t.Fatalf("units: %v", units)
+	}
+
+	for i, e := range expected {
+		u := units[i]
+		if u.AccountID != e.AccountID || u.Family != e.Family || u.Date != e.Date || u.NormalizedUnits != e.Units || u.SizeMixString() != e.SizeMix || u.Total != e.Total {
+			t.Errorf("expected: %v, actual: %v", e, u)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Rules selecting the canonical price of a Key, in order of precedence.
+const (
+	CapacityStatusRule = "capacity_status" // Used (or no capacity status) over capacity reservations
+	VersionRule        = "version"         // the newer price list version
+	SKURule            = "sku"             // the smaller SKU. the prices are ambiguous.
+)
+
+const (
+	CapacityStatusUsed = "Used"
+	LicenseModelBYOL   = "Bring your own license"
+)
+
+// Duplicate is a price dropped in favor of the canonical price of the same Key.
+type Duplicate struct {
+	Key      string
+	Rule     string
+	Selected Price
+	Dropped  Price
+}
+
+// Key returns the identity of a price:
+// region, usage type, os/engine/edition, pre installed software, license model, tenancy, lease contract length, purchase option and offering class.
+func Key(p Price) string {
+	return fmt.Sprintf(
+		"%s/%s/%s%s%s%s/%s/%s/%s/%s/%s/%s",
+		p.Region,
+		p.UsageType,
+		p.OperatingSystem,
+		p.CacheEngine,
+		p.DatabaseEngine,
+		p.DatabaseEdition,
+		p.PreInstalled,
+		p.LicenseModel,
+		p.Tenancy,
+		p.LeaseContractLength,
+		p.PurchaseOption,
+		p.OfferingClass,
+	)
+}
+
+// Dedupe returns the canonical price of each Key in the order of plist
+// and the duplicates dropped, sorted by Key.
+func Dedupe(plist []Price) ([]Price, []Duplicate) {
+	index := make(map[string]int)
+	group := make(map[string][]Price)
+
+	out := make([]Price, 0)
+	for _, p := range plist {
+		k := Key(p)
+		group[k] = append(group[k], p)
+
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(out)
+			out = append(out, p)
+			continue
+		}
+
+		if ok, _ := prefer(p, out[i]); ok {
+			out[i] = p
+		}
+	}
+
+	dup := make([]Duplicate, 0)
+	for _, p := range out {
+		k := Key(p)
+		for _, d := range group[k] {
+			if d == p {
+				continue
+			}
+
+			_, rule := prefer(p, d)
+			dup = append(dup, Duplicate{Key: k, Rule: rule, Selected: p, Dropped: d})
+		}
+	}
+
+	sort.SliceStable(dup, func(i, j int) bool { return dup[i].Key < dup[j].Key })
+
+	return out, dup
+}
+
+// prefer returns true when a is preferred to b, and the rule deciding it.
+func prefer(a, b Price) (bool, string) {
+	au, bu := used(a), used(b)
+	if au != bu {
+		return au, CapacityStatusRule
+	}
+
+	if a.Version != b.Version {
+		return a.Version > b.Version, VersionRule
+	}
+
+	return a.SKU < b.SKU, SKURule
+}
+
+func used(p Price) bool {
+	return len(p.CapacityStatus) < 1 || p.CapacityStatus == CapacityStatusUsed
+}
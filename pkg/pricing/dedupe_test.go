@@ -0,0 +1,54 @@
+package pricing
+
+import "testing"
+
+func TestDedupe(t *testing.T) {
+	price := func(sku, version, capacity, license string) Price {
+		return Price{
+			Version:             version,
+			SKU:                 sku,
+			OfferTermCode:       "6QCMYABX3D",
+			Region:              "ap-northeast-1",
+			UsageType:           "APN1-BoxUsage:c4.large",
+			LeaseContractLength: "1yr",
+			PurchaseOption:      "All Upfront",
+			OfferingClass:       "standard",
+			OperatingSystem:     "Windows",
+			PreInstalled:        "NA",
+			Tenancy:             "Shared",
+			CapacityStatus:      capacity,
+			LicenseModel:        license,
+		}
+	}
+
+	plist := []Price{
+		price("SKU4", "20190730012138", "UnusedCapacityReservation", "License Included"),
+		price("SKU3", "20190730012138", "Used", LicenseModelBYOL),
+		price("SKU2", "20190730012138", "Used", "License Included"),
+		price("SKU1", "20190601000000", "Used", "License Included"),
+		price("SKU0", "20190730012138", "Used", "License Included"),
+		{SKU: "SKU5", OfferTermCode: "6QCMYABX3D", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.xlarge"},
+	}
+
+	out, dup := Dedupe(plist)
+	// SKU3 is not a duplicate of a license included price.
+	if len(out) != 3 || out[0].SKU != "SKU0" || out[1].SKU != "SKU3" || out[2].SKU != "SKU5" {
+		t.Fatalf("out: %v", out)
+	}
+
+	expected := map[string]string{
+		"SKU4": CapacityStatusRule,
+		"SKU2": SKURule,
+		"SKU1": VersionRule,
+	}
+
+	if len(dup) != len(expected) {
+		t.Fatalf("dup: %v", dup)
+	}
+
+	for _, d := range dup {
+		if d.Selected.SKU != "SKU0" || d.Rule != expected[d.Dropped.SKU] {
+			t.Errorf("dropped %s: %v", d.Dropped.SKU, d)
+		}
+	}
+}
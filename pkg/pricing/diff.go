@@ -0,0 +1,86 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	Added            = "added"
+	Removed          = "removed"
+	Changed          = "changed"
+	NewOfferingClass = "offering_class"
+)
+
+// Change is a difference of a price between two price lists. From is empty for Added, To is empty for Removed.
+type Change struct {
+	Kind string
+	From Price
+	To   Price
+}
+
+func (c Change) Price() Price {
+	if c.Kind == Removed {
+		return c.From
+	}
+
+	return c.To
+}
+
+// Diff returns the prices added to and removed from to by SKU and OfferTermCode,
+// the prices whose on demand or reserved rates changed,
+// and the offering classes new to a usage type.
+func Diff(from, to []Price) []Change {
+	key := func(p Price) string { return fmt.Sprintf("%s.%s", p.SKU, p.OfferTermCode) }
+
+	fp := make(map[string]Price)
+	class := make(map[string]bool)
+	usageType := make(map[string]bool)
+	for _, p := range from {
+		fp[key(p)] = p
+		class[fmt.Sprintf("%s/%s", p.UsageType, p.OfferingClass)] = true
+		usageType[p.UsageType] = true
+	}
+
+	tp := make(map[string]Price)
+	for _, p := range to {
+		tp[key(p)] = p
+	}
+
+	out := make([]Change, 0)
+	for k, p := range tp {
+		f, ok := fp[k]
+		if !ok {
+			out = append(out, Change{Kind: Added, To: p})
+			continue
+		}
+
+		if f.OnDemand != p.OnDemand || f.ReservedQuantity != p.ReservedQuantity || f.ReservedHrs != p.ReservedHrs {
+			out = append(out, Change{Kind: Changed, From: f, To: p})
+		}
+	}
+
+	for k, p := range fp {
+		if _, ok := tp[k]; !ok {
+			out = append(out, Change{Kind: Removed, From: p})
+		}
+	}
+
+	added := make(map[string]bool)
+	for _, p := range to {
+		c := fmt.Sprintf("%s/%s", p.UsageType, p.OfferingClass)
+		if len(p.OfferingClass) < 1 || class[c] || added[c] || !usageType[p.UsageType] {
+			continue
+		}
+
+		added[c] = true
+		out = append(out, Change{Kind: NewOfferingClass, To: p})
+	}
+
+	order := map[string]int{Added: 0, Removed: 1, Changed: 2, NewOfferingClass: 3}
+	sort.SliceStable(out, func(i, j int) bool { return key(out[i].Price()) < key(out[j].Price()) })
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Price().UsageType < out[j].Price().UsageType })
+	sort.SliceStable(out, func(i, j int) bool { return order[out[i].Kind] < order[out[j].Kind] })
+
+	return out
+}
@@ -0,0 +1,46 @@
+package pricing
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	from := []Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.large", OfferingClass: "standard", OnDemand: 0.126, ReservedQuantity: 738},
+		{SKU: "SKU1", OfferTermCode: "HU7G6KETJZ", UsageType: "APN1-BoxUsage:c4.large", OfferingClass: "standard", OnDemand: 0.126, ReservedHrs: 0.04},
+		{SKU: "SKU2", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c3.large", OfferingClass: "standard", OnDemand: 0.128, ReservedQuantity: 800},
+	}
+
+	to := []Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.large", OfferingClass: "standard", OnDemand: 0.12, ReservedQuantity: 700},
+		{SKU: "SKU1", OfferTermCode: "HU7G6KETJZ", UsageType: "APN1-BoxUsage:c4.large", OfferingClass: "standard", OnDemand: 0.126, ReservedHrs: 0.04},
+		{SKU: "SKU1", OfferTermCode: "VJWZNREJX2", UsageType: "APN1-BoxUsage:c4.large", OfferingClass: "convertible", OnDemand: 0.126, ReservedQuantity: 900},
+		{SKU: "SKU3", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c5.large", OfferingClass: "standard", OnDemand: 0.107, ReservedQuantity: 600},
+	}
+
+	expected := []struct {
+		Kind          string
+		SKU           string
+		OfferTermCode string
+	}{
+		{Added, "SKU1", "VJWZNREJX2"},
+		{Added, "SKU3", "6QCMYABX3D"},
+		{Removed, "SKU2", "6QCMYABX3D"},
+		{Changed, "SKU1", "6QCMYABX3D"},
+		{NewOfferingClass, "SKU1", "VJWZNREJX2"},
+	}
+
+	diff := Diff(from, to)
+	if len(diff) != len(expected) {
+		t.Fatalf("diff: %v", diff)
+	}
+
+	for i, e := range expected {
+		p := diff[i].Price()
+		if diff[i].Kind != e.Kind || p.SKU != e.SKU || p.OfferTermCode != e.OfferTermCode {
+			t.Errorf("expected: %v, actual: %v", e, diff[i])
+		}
+	}
+
+	if diff[3].From.OnDemand != 0.126 || diff[3].To.OnDemand != 0.12 {
+		t.Errorf("changed: %v", diff[3])
+	}
+}
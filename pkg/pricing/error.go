@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNormalizationSizeFactor = errors.New("invalid normalization size factor")
+	ErrOfferTermCode           = errors.New("inconsistent offer term code")
+)
+
+// PriceError is a price skipped because of Err.
+type PriceError struct {
+	SKU           string
+	OfferTermCode string
+	UsageType     string
+	Err           error
+}
+
+func NewPriceError(p Price, err error) PriceError {
+	return PriceError{
+		SKU:           p.SKU,
+		OfferTermCode: p.OfferTermCode,
+		UsageType:     p.UsageType,
+		Err:           err,
+	}
+}
+
+func (e PriceError) Error() string {
+	return fmt.Sprintf("%s.%s (%s): %v", e.SKU, e.OfferTermCode, e.UsageType, e.Err)
+}
+
+// InvalidError lists the prices skipped.
+// Family, Minimum and hermes.Normalize return it together with the result of the other prices.
+type InvalidError struct {
+	Invalid []PriceError
+}
+
+func (e *InvalidError) Error() string {
+	msg := make([]string, 0)
+	for _, i := range e.Invalid {
+		msg = append(msg, i.Error())
+	}
+
+	return fmt.Sprintf("%d invalid prices: %s", len(e.Invalid), strings.Join(msg, "; "))
+}
+
+// SizeFactor returns the normalization size factor of p.
+// ok is false when p has no factor (empty or NA).
+func SizeFactor(p Price) (float64, bool, error) {
+	if len(p.NormalizationSizeFactor) < 1 || p.NormalizationSizeFactor == "NA" {
+		return 0, false, nil
+	}
+
+	f, err := strconv.ParseFloat(p.NormalizationSizeFactor, 64)
+	if err != nil || f <= 0 {
+		return 0, false, ErrNormalizationSizeFactor
+	}
+
+	return f, true, nil
+}
@@ -2,66 +2,75 @@ package pricing
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 )
 
-func Family(plist []Price) map[string]Price {
+// familyKey returns the key of the instance family of p.
+// The license model only distinguishes BYOL as TupleKey does.
+func familyKey(p Price) string {
+	license := ""
+	if p.LicenseModel == LicenseModelBYOL {
+		license = "BYOL"
+	}
+
+	return fmt.Sprintf(
+		"%s%s%s%s%s%s%s%s%s%s%s%s%s",
+		p.UsageType[:strings.LastIndex(p.UsageType, ".")],
+		p.OperatingSystem,
+		p.PreInstalled,
+		license,
+		p.CacheEngine,
+		p.DatabaseEngine,
+		p.DatabaseEdition,
+		p.Region,
+		p.OfferingClass,
+		p.Tenancy,
+		p.PurchaseOption,
+		p.LeaseContractLength,
+		p.Version,
+	)
+}
+
+// Family returns the price with the smallest normalization size factor in each instance family.
+// plist is deduplicated with Dedupe beforehand.
+// Prices with an invalid normalization size factor are skipped and returned in an *InvalidError.
+func Family(plist []Price) (map[string]Price, error) {
+	plist, _ = Dedupe(plist)
+
+	invalid := make([]PriceError, 0)
 	mmap := make(map[string]Price)
 	for i := range plist {
 		if strings.LastIndex(plist[i].UsageType, ".") < 0 {
 			continue
 		}
 
-		hash := fmt.Sprintf(
-			//		"%s%s%s%s%s%s%s%s%s%s%s%s",
-			"%s%s%s%s%s%s%s%s%s%s",
-			plist[i].UsageType[:strings.LastIndex(plist[i].UsageType, ".")],
-			plist[i].OperatingSystem,
-			plist[i].CacheEngine,
-			plist[i].DatabaseEngine,
-			//			plist[i].Operation,
-			//			plist[i].PreInstalled,
-			plist[i].Region,
-			plist[i].OfferingClass,
-			plist[i].Tenancy,
-			plist[i].PurchaseOption,
-			plist[i].LeaseContractLength,
-			plist[i].Version,
-		)
+		hash := familyKey(plist[i])
 
-		v, ok := mmap[hash]
-		if !ok {
-			mmap[hash] = plist[i]
-			continue
-		}
-
-		if len(v.NormalizationSizeFactor) < 1 {
+		f, ok, err := SizeFactor(plist[i])
+		if err != nil {
+			invalid = append(invalid, NewPriceError(plist[i], err))
 			continue
 		}
 
-		if v.NormalizationSizeFactor == "NA" {
+		v, found := mmap[hash]
+		if !found {
+			mmap[hash] = plist[i]
 			continue
 		}
 
-		if plist[i].NormalizationSizeFactor == "NA" {
+		vf, vok, _ := SizeFactor(v)
+		if !vok || !ok {
 			continue
 		}
 
-		s0, err := strconv.ParseFloat(v.NormalizationSizeFactor, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid normalization size factor: %v", err))
-		}
-
-		s1, err := strconv.ParseFloat(plist[i].NormalizationSizeFactor, 64)
-		if err != nil {
-			panic(fmt.Sprintf("invalid normalization size factor: %v", err))
-		}
-
-		if s0 > s1 {
+		if vf > f {
 			mmap[hash] = plist[i]
 		}
 	}
 
-	return mmap
+	if len(invalid) > 0 {
+		return mmap, &InvalidError{Invalid: invalid}
+	}
+
+	return mmap, nil
 }
@@ -11,8 +11,68 @@ func TestFamily(t *testing.T) {
 		t.Errorf("desirialize pricing: %v", err)
 	}
 
-	family := Family(plist)
+	family, err := Family(plist)
+	if err != nil {
+		t.Errorf("family: %v", err)
+	}
+
 	for _, v := range family {
 		fmt.Println(v)
 	}
 }
+
+func TestFamilyInvalid(t *testing.T) {
+	plist := []Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.xlarge", NormalizationSizeFactor: "8"},
+		{SKU: "SKU2", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.large", NormalizationSizeFactor: "four"},
+		{SKU: "SKU3", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.2xlarge", NormalizationSizeFactor: "16"},
+	}
+
+	family, err := Family(plist)
+	ierr, ok := err.(*InvalidError)
+	if !ok {
+		t.Fatalf("expected invalid error: %v", err)
+	}
+
+	if len(ierr.Invalid) != 1 || ierr.Invalid[0].SKU != "SKU2" || ierr.Invalid[0].Err != ErrNormalizationSizeFactor {
+		t.Errorf("invalid: %v", ierr.Invalid)
+	}
+
+	if len(family) != 1 {
+		t.Fatalf("family: %v", family)
+	}
+
+	for _, v := range family {
+		if v.SKU != "SKU1" {
+			t.Errorf("family: %v", v)
+		}
+	}
+}
+
+func TestFamilyLicense(t *testing.T) {
+	plist := []Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:m5.xlarge", OperatingSystem: "Windows", PreInstalled: "NA", LicenseModel: "No License required", NormalizationSizeFactor: "8"},
+		{SKU: "SKU2", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:m5.2xlarge", OperatingSystem: "Windows", PreInstalled: "SQL Web", LicenseModel: "No License required", NormalizationSizeFactor: "16"},
+		{SKU: "SKU3", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:m5.4xlarge", OperatingSystem: "Windows", PreInstalled: "NA", LicenseModel: LicenseModelBYOL, NormalizationSizeFactor: "32"},
+	}
+
+	family, err := Family(plist)
+	if err != nil {
+		t.Fatalf("family: %v", err)
+	}
+
+	if len(family) != 3 {
+		t.Fatalf("family: %v", family)
+	}
+
+	mini, err := Minimum(family, plist)
+	if err != nil {
+		t.Fatalf("minimum: %v", err)
+	}
+
+	for _, v := range mini {
+		if v.Minimum.SKU != v.Price.SKU {
+			t.Errorf("price: %v, minimum: %v", v.Price.SKU, v.Minimum.SKU)
+		}
+	}
+}
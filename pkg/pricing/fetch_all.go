@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type FetchAllInput struct {
+	URL      []string
+	Region   []string
+	Parallel int
+	Client   *http.Client
+	Progress func(done, total int, url, region string, err error)
+}
+
+type Result struct {
+	Region string
+	Price  []Price
+	Err    error
+}
+
+type job struct {
+	URL    string
+	Region string
+	Index  InputPrice
+}
+
+type jobResult struct {
+	job
+	Price map[string]Price
+	Err   error
+}
+
+// FetchAll fetches the region index of each service once
+// and downloads the regional offer files with in.Parallel workers.
+// f is called once per region, as soon as all of its offer files are fetched.
+// A failure in one region does not abort the others.
+func FetchAll(in FetchAllInput, f func(Result)) {
+	if in.Client == nil {
+		in.Client = http.DefaultClient
+	}
+
+	if in.Parallel < 1 {
+		in.Parallel = 1
+	}
+
+	index := make(map[string]InputPrice)
+	failed := make(map[string]error)
+	for _, url := range in.URL {
+		input, err := FetchIndex(url, in.Client)
+		if err != nil {
+			failed[url] = err
+			continue
+		}
+
+		index[url] = input
+	}
+
+	jobs := make([]job, 0)
+	pending := make(map[string]int)
+	result := make(map[string]*Result)
+	for _, r := range in.Region {
+		if _, ok := result[r]; ok {
+			continue
+		}
+
+		result[r] = &Result{Region: r, Price: make([]Price, 0)}
+		for _, url := range in.URL {
+			if err, ok := failed[url]; ok {
+				if result[r].Err == nil {
+					result[r].Err = fmt.Errorf("fetch index %s: %v", Service(url), err)
+				}
+				continue
+			}
+
+			jobs = append(jobs, job{URL: url, Region: r, Index: index[url]})
+			pending[r]++
+		}
+	}
+
+	for r := range result {
+		if pending[r] == 0 {
+			f(*result[r])
+		}
+	}
+
+	queue := make(chan job)
+	done := make(chan jobResult)
+	for i := 0; i < in.Parallel; i++ {
+		go func() {
+			for j := range queue {
+				p, err := FetchRegion(j.URL, j.Index, j.Region, in.Client)
+				done <- jobResult{job: j, Price: p, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for _, j := range jobs {
+			queue <- j
+		}
+		close(queue)
+	}()
+
+	for i := range jobs {
+		j := <-done
+		if in.Progress != nil {
+			in.Progress(i+1, len(jobs), j.URL, j.Region, j.Err)
+		}
+
+		r := result[j.Region]
+		if j.Err != nil && r.Err == nil {
+			r.Err = fmt.Errorf("fetch %s: %v", Service(j.URL), j.Err)
+		}
+
+		if r.Err == nil {
+			for k := range j.Price {
+				r.Price = append(r.Price, j.Price[k])
+			}
+		}
+
+		pending[j.Region]--
+		if pending[j.Region] > 0 {
+			continue
+		}
+
+		if r.Err != nil {
+			r.Price = nil
+		}
+
+		f(*r)
+		delete(result, j.Region)
+	}
+}
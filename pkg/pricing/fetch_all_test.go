@@ -0,0 +1,192 @@
+package pricing
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+)
+
+var offer = `{
+  "version": "20190730012138",
+  "products": {
+    "SKU0000000000001": {
+      "sku": "SKU0000000000001",
+      "attributes": {"instanceType": "c4.large", "usagetype": "%s-BoxUsage:c4.large", "operatingSystem": "Linux", "tenancy": "Shared", "preInstalledSw": "NA", "normalizationSizeFactor": "4", "vcpu": "2", "memory": "3.75 GiB", "currentGeneration": "Yes", "capacitystatus": "Used", "location": "Asia Pacific (Tokyo)"}
+    }
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU0000000000001": {
+        "SKU0000000000001.JRTCKXETXF": {"sku": "SKU0000000000001", "offerTermCode": "JRTCKXETXF", "priceDimensions": {"d": {"unit": "Hrs", "pricePerUnit": {"USD": "0.126"}}}}
+      }
+    },
+    "Reserved": {
+      "SKU0000000000001": {
+        "SKU0000000000001.6QCMYABX3D": {
+          "sku": "SKU0000000000001",
+          "offerTermCode": "6QCMYABX3D",
+          "priceDimensions": {"d": {"unit": "Quantity", "pricePerUnit": {"USD": "738"}}},
+          "termAttributes": {"LeaseContractLength": "1yr", "OfferingClass": "standard", "PurchaseOption": "All Upfront"}
+        }
+      }
+    }
+  }
+}`
+
+func newOfferServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/offers/v1.0/aws/AmazonEC2/current/region_index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"regions": {
+			"ap-northeast-1": {"regionCode": "ap-northeast-1", "currentVersionUrl": "/offers/v1.0/aws/AmazonEC2/20190730012138/ap-northeast-1/index.json"},
+			"us-west-2": {"regionCode": "us-west-2", "currentVersionUrl": "/offers/v1.0/aws/AmazonEC2/20190730012138/us-west-2/index.json"}
+		}}`)
+	})
+	mux.HandleFunc("/offers/v1.0/aws/AmazonEC2/20190730012138/ap-northeast-1/index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, offer, "APN1")
+	})
+	mux.HandleFunc("/offers/v1.0/aws/AmazonEC2/20190730012138/us-west-2/index.json", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/offers/v1.0/aws/AmazonRedshift/current/region_index.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"regions": {}}`)
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestFetchAll(t *testing.T) {
+	s := newOfferServer()
+	defer s.Close()
+
+	var mu sync.Mutex
+	progress := 0
+	result := make(map[string]Result)
+	FetchAll(FetchAllInput{
+		URL: []string{
+			fmt.Sprintf("%s/offers/v1.0/aws/AmazonEC2/current/region_index.json", s.URL),
+			fmt.Sprintf("%s/offers/v1.0/aws/AmazonRedshift/current/region_index.json", s.URL),
+		},
+		Region:   []string{"ap-northeast-1", "us-west-2"},
+		Parallel: 2,
+		Progress: func(done, total int, url, region string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress++
+		},
+	}, func(r Result) {
+		result[r.Region] = r
+	})
+
+	if progress != 4 {
+		t.Errorf("progress: %v", progress)
+	}
+
+	apn1 := result["ap-northeast-1"]
+	if apn1.Err != nil {
+		t.Errorf("fetch: %v", apn1.Err)
+	}
+
+	if len(apn1.Price) != 1 {
+		t.Fatalf("price: %v", apn1.Price)
+	}
+
+	expected := Price{
+		Version:                 "20190730012138",
+		SKU:                     "SKU0000000000001",
+		OfferTermCode:           "6QCMYABX3D",
+		Region:                  "ap-northeast-1",
+		InstanceType:            "c4.large",
+		UsageType:               "APN1-BoxUsage:c4.large",
+		LeaseContractLength:     "1yr",
+		PurchaseOption:          "All Upfront",
+		OnDemand:                0.126,
+		ReservedQuantity:        738,
+		Tenancy:                 "Shared",
+		PreInstalled:            "NA",
+		OperatingSystem:         "Linux",
+		OfferingClass:           "standard",
+		NormalizationSizeFactor: "4",
+		VCPU:                    "2",
+		Memory:                  "3.75 GiB",
+		CurrentGeneration:       "Yes",
+		CapacityStatus:          "Used",
+		Location:                "Asia Pacific (Tokyo)",
+	}
+
+	if apn1.Price[0] != expected {
+		t.Errorf("expected: %v, actual: %v", expected, apn1.Price[0])
+	}
+
+	if result["us-west-2"].Err == nil || len(result["us-west-2"].Price) != 0 {
+		t.Errorf("expected error: %v", result["us-west-2"])
+	}
+}
+
+func TestFetchAllIndexError(t *testing.T) {
+	s := newOfferServer()
+	defer s.Close()
+
+	region := make([]string, 0)
+	FetchAll(FetchAllInput{
+		URL:    []string{fmt.Sprintf("%s/notfound/region_index.json", s.URL)},
+		Region: []string{"ap-northeast-1", "us-west-2"},
+	}, func(r Result) {
+		if r.Err == nil {
+			t.Errorf("expected error: %v", r)
+		}
+
+		region = append(region, r.Region)
+	})
+
+	sort.Strings(region)
+	if len(region) != 2 || region[0] != "ap-northeast-1" || region[1] != "us-west-2" {
+		t.Errorf("region: %v", region)
+	}
+}
+
+func TestService(t *testing.T) {
+	cases := []struct {
+		URL     string
+		Service string
+	}{
+		{Compute, "AmazonEC2"},
+		{Database, "AmazonRDS"},
+		{Cache, "AmazonElastiCache"},
+		{Redshift, "AmazonRedshift"},
+		{OpenSearch, "AmazonES"},
+		{DynamoDB, "AmazonDynamoDB"},
+		{MemoryDB, "AmazonMemoryDB"},
+	}
+
+	for _, tt := range cases {
+		if Service(tt.URL) != tt.Service {
+			t.Errorf("expected: %v, actual: %v", tt.Service, Service(tt.URL))
+		}
+	}
+}
+
+func TestFetchRegionList(t *testing.T) {
+	s := newOfferServer()
+	defer s.Close()
+
+	url := URLList(s.URL + "/")
+	if url[0] != s.URL+"/offers/v1.0/aws/AmazonEC2/current/region_index.json" {
+		t.Errorf("url: %v", url)
+	}
+
+	region, err := FetchRegionList([]string{url[0], url[3]}, s.Client())
+	if err != nil {
+		t.Fatalf("fetch region list: %v", err)
+	}
+
+	if len(region) != 2 || region[0] != "ap-northeast-1" || region[1] != "us-west-2" {
+		t.Errorf("region: %v", region)
+	}
+
+	if _, err := FetchRegionList(url, s.Client()); err == nil {
+		t.Errorf("expected error")
+	}
+}
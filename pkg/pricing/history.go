@@ -0,0 +1,283 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+)
+
+// NewSnapshot returns the id of a snapshot of prices taken at t.
+func NewSnapshot(t time.Time) string {
+	return t.UTC().Format("20060102150405")
+}
+
+// Version returns the distinct price list versions of price in ascending order.
+func Version(price []Price) []string {
+	version := make(map[string]bool)
+	for _, p := range price {
+		version[p.Version] = true
+	}
+
+	out := make([]string, 0)
+	for v := range version {
+		out = append(out, v)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// SameVersion returns true when a and b are built from the same price list versions.
+func SameVersion(a, b []Price) bool {
+	return strings.Join(Version(a), ",") == strings.Join(Version(b), ",")
+}
+
+// SerializeSnapshot writes the price list versions of a snapshot of region to {dir}/pricing/history/{region}/snapshot/{snapshot}.out
+// unless the latest snapshot of region has the same versions.
+// The prices of a version are in {dir}/pricing/{region}.out while it is current, and archived by ArchiveVersion after that.
+func SerializeSnapshot(dir, region, snapshot string, version []string, opt cache.Option) error {
+	list, err := ListSnapshot(dir, region)
+	if err != nil {
+		return err
+	}
+
+	if len(list) > 0 {
+		latest, err := SnapshotVersion(dir, region, list[len(list)-1])
+		if err != nil {
+			return err
+		}
+
+		if strings.Join(latest, ",") == strings.Join(version, ",") {
+			return nil
+		}
+	}
+
+	path := fmt.Sprintf("%s/pricing/history/%s/snapshot", dir, region)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		os.MkdirAll(path, os.ModePerm)
+	}
+
+	if len(opt.Source) < 1 {
+		opt.Source = BaseURL
+	}
+
+	bytes, err := cache.Encode(SchemaVersion, version, opt)
+	if err != nil {
+		return fmt.Errorf("encode: %v", err)
+	}
+
+	if err := cache.WriteFile(fmt.Sprintf("%s/%s.out", path, snapshot), bytes); err != nil {
+		return fmt.Errorf("write file: %v", err)
+	}
+
+	return nil
+}
+
+// ArchiveVersion writes the prices of each version of price not in current to {dir}/pricing/history/{region}/version/{version}.out.
+// Only the versions of a snapshot are archived, once.
+func ArchiveVersion(dir, region string, price []Price, current []string, opt cache.Option) error {
+	keep := make(map[string]bool)
+	for _, v := range current {
+		keep[v] = true
+	}
+
+	snapshot, err := ListSnapshot(dir, region)
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string]bool)
+	for _, s := range snapshot {
+		version, err := SnapshotVersion(dir, region, s)
+		if err != nil {
+			return err
+		}
+
+		for _, v := range version {
+			listed[v] = true
+		}
+	}
+
+	archive := make(map[string][]Price)
+	for _, p := range price {
+		if keep[p.Version] || !listed[p.Version] {
+			continue
+		}
+
+		archive[p.Version] = append(archive[p.Version], p)
+	}
+
+	path := fmt.Sprintf("%s/pricing/history/%s/version", dir, region)
+	for v, p := range archive {
+		file := fmt.Sprintf("%s/%s.out", path, v)
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			continue
+		}
+
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			os.MkdirAll(path, os.ModePerm)
+		}
+
+		if len(opt.Source) < 1 {
+			opt.Source = BaseURL
+		}
+
+		bytes, err := cache.Encode(SchemaVersion, p, opt)
+		if err != nil {
+			return fmt.Errorf("encode: %v", err)
+		}
+
+		if err := cache.WriteFile(file, bytes); err != nil {
+			return fmt.Errorf("write file: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// ListSnapshot returns the snapshots of region in ascending order.
+// The snapshots written as a full copy of the prices by an older version are included.
+func ListSnapshot(dir, region string) ([]string, error) {
+	out := make([]string, 0)
+	for _, pattern := range []string{
+		fmt.Sprintf("%s/pricing/history/%s/snapshot/*.out", dir, region),
+		fmt.Sprintf("%s/pricing/history/%s/*.out", dir, region),
+	} {
+		file, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("glob: %v", err)
+		}
+
+		for _, f := range file {
+			out = append(out, strings.TrimSuffix(filepath.Base(f), ".out"))
+		}
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+// SnapshotVersion returns the price list versions of a snapshot of region.
+func SnapshotVersion(dir, region, snapshot string) ([]string, error) {
+	file := fmt.Sprintf("%s/pricing/history/%s/snapshot/%s.out", dir, region, snapshot)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		price, err := deserializeFullSnapshot(dir, region, snapshot)
+		if err != nil {
+			return nil, err
+		}
+
+		return Version(price), nil
+	}
+
+	read, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %v", file, err)
+	}
+
+	version, err := DecodeVersion(read)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	return version, nil
+}
+
+// DecodeVersion returns the price list versions of a snapshot file.
+func DecodeVersion(b []byte) ([]string, error) {
+	h, data, err := cache.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.Check(h, SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	var version []string
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	return version, nil
+}
+
+// DeserializeSnapshot returns the prices of a snapshot of region,
+// reading each version from its archive or {dir}/pricing/{region}.out when it is current.
+func DeserializeSnapshot(dir, region, snapshot string) ([]Price, error) {
+	file := fmt.Sprintf("%s/pricing/history/%s/snapshot/%s.out", dir, region, snapshot)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return deserializeFullSnapshot(dir, region, snapshot)
+	}
+
+	version, err := SnapshotVersion(dir, region, snapshot)
+	if err != nil {
+		return []Price{}, err
+	}
+
+	var current []Price
+	out := make([]Price, 0)
+	for _, v := range version {
+		file := fmt.Sprintf("%s/pricing/history/%s/version/%s.out", dir, region, v)
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			price, err := readFile(file)
+			if err != nil {
+				return []Price{}, err
+			}
+
+			out = append(out, price...)
+			continue
+		}
+
+		if current == nil {
+			current, err = Deserialize(dir, []string{region})
+			if err != nil {
+				return []Price{}, fmt.Errorf("version %s: %v", v, err)
+			}
+		}
+
+		found := false
+		for _, p := range current {
+			if p.Version == v {
+				out = append(out, p)
+				found = true
+			}
+		}
+
+		if !found {
+			return []Price{}, fmt.Errorf("version not found: %s/%s", region, v)
+		}
+	}
+
+	return out, nil
+}
+
+// deserializeFullSnapshot reads {dir}/pricing/history/{region}/{snapshot}.out written by an older version.
+func deserializeFullSnapshot(dir, region, snapshot string) ([]Price, error) {
+	file := fmt.Sprintf("%s/pricing/history/%s/%s.out", dir, region, snapshot)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return []Price{}, fmt.Errorf("file not found: %v", file)
+	}
+
+	return readFile(file)
+}
+
+func readFile(file string) ([]Price, error) {
+	read, err := ioutil.ReadFile(file)
+	if err != nil {
+		return []Price{}, fmt.Errorf("read %s: %v", file, err)
+	}
+
+	price, err := Decode(read)
+	if err != nil {
+		return []Price{}, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	return price, nil
+}
@@ -0,0 +1,81 @@
+package pricing
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+)
+
+func TestSerializeSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	v1 := []Price{{Version: "20190730012138", SKU: "SKU1", OfferTermCode: "6QCMYABX3D", OnDemand: 0.126}}
+	v2 := []Price{{Version: "20190815012138", SKU: "SKU1", OfferTermCode: "6QCMYABX3D", OnDemand: 0.12}}
+
+	cases := []struct {
+		Snapshot string
+		Price    []Price
+	}{
+		{"20190801000000", v1},
+		{"20190810000000", v1},
+		{"20190820000000", v2},
+	}
+
+	// as File.WritePricing
+	for _, c := range cases {
+		if cached, err := Deserialize(dir, []string{"ap-northeast-1"}); err == nil {
+			if err := ArchiveVersion(dir, "ap-northeast-1", cached, Version(c.Price), cache.Option{}); err != nil {
+				t.Errorf("archive version: %v", err)
+			}
+		}
+
+		if err := SerializeWithOption(dir, "ap-northeast-1", c.Price, cache.Option{Overwrite: true}); err != nil {
+			t.Errorf("serialize: %v", err)
+		}
+
+		if err := SerializeSnapshot(dir, "ap-northeast-1", c.Snapshot, Version(c.Price), cache.Option{}); err != nil {
+			t.Errorf("serialize snapshot: %v", err)
+		}
+	}
+
+	snapshot, err := ListSnapshot(dir, "ap-northeast-1")
+	if err != nil {
+		t.Errorf("list snapshot: %v", err)
+	}
+
+	// the second snapshot has the same version as the first one.
+	if len(snapshot) != 2 || snapshot[0] != "20190801000000" || snapshot[1] != "20190820000000" {
+		t.Fatalf("snapshot: %v", snapshot)
+	}
+
+	expected := [][]Price{v1, v2}
+	for i := range snapshot {
+		p, err := DeserializeSnapshot(dir, "ap-northeast-1", snapshot[i])
+		if err != nil {
+			t.Errorf("deserialize snapshot: %v", err)
+		}
+
+		if len(p) != 1 || p[0] != expected[i][0] {
+			t.Errorf("expected: %v, actual: %v", expected[i], p)
+		}
+	}
+
+	// v1 is archived. v2 is read from the current prices.
+	if _, err := os.Stat(dir + "/pricing/history/ap-northeast-1/version/20190730012138.out"); err != nil {
+		t.Errorf("archive: %v", err)
+	}
+
+	if _, err := os.Stat(dir + "/pricing/history/ap-northeast-1/version/20190815012138.out"); !os.IsNotExist(err) {
+		t.Errorf("current version archived: %v", err)
+	}
+
+	if _, err := DeserializeSnapshot(dir, "ap-northeast-1", "20190810000000"); err == nil {
+		t.Errorf("expected error")
+	}
+}
@@ -0,0 +1,272 @@
+package pricing
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// csvAttribute is the product attribute of a CSV offer file column
+// whose name is not the lower camel case of the column.
+var csvAttribute = map[string]string{
+	"usageType":         "usagetype",
+	"Pre Installed S/W": "preInstalledSw",
+	"CapacityStatus":    "capacitystatus",
+	"Instance Type":     "instanceType",
+	"Region Code":       "regionCode",
+	"vCPU":              "vcpu",
+	"ECU":               "ecu",
+	"GPU":               "gpu",
+}
+
+// csvMeta is the number of metadata lines before the header of a CSV offer file.
+const csvMeta = 5
+
+// ReadFile returns the offer file downloaded from the price list api.
+// file is a JSON (.json) or CSV (.csv) offer file, optionally gzip compressed (.gz).
+func ReadFile(file string) (PriceList, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return PriceList{}, fmt.Errorf("open: %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	name := file
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return PriceList{}, fmt.Errorf("gzip: %v", err)
+		}
+		defer gz.Close()
+
+		r = gz
+		name = strings.TrimSuffix(name, ".gz")
+	}
+
+	if strings.HasSuffix(name, ".csv") {
+		return ReadCSV(r)
+	}
+
+	buf, err := ioutil.ReadAll(r)
+	if err != nil {
+		return PriceList{}, fmt.Errorf("read: %v", err)
+	}
+
+	var list PriceList
+	if err := json.Unmarshal(buf, &list); err != nil {
+		return PriceList{}, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	return list, nil
+}
+
+// ReadCSV returns the price list of a CSV offer file.
+func ReadCSV(r io.Reader) (PriceList, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	list := PriceList{
+		Products: make(map[string]Product),
+		Terms:    make(map[string]map[string]map[string]Term),
+	}
+
+	for i := 0; i < csvMeta; i++ {
+		meta, err := cr.Read()
+		if err != nil {
+			return PriceList{}, fmt.Errorf("read metadata: %v", err)
+		}
+
+		if len(meta) < 2 {
+			continue
+		}
+
+		switch strings.TrimPrefix(meta[0], "\ufeff") {
+		case "FormatVersion":
+			list.FormatVersion = meta[1]
+		case "Disclaimer":
+			list.Disclaimer = meta[1]
+		case "Publication Date":
+			list.PublicationDate = meta[1]
+		case "Version":
+			list.Version = meta[1]
+		case "OfferCode":
+			list.OfferCode = meta[1]
+		}
+	}
+
+	header, err := cr.Read()
+	if err != nil {
+		return PriceList{}, fmt.Errorf("read header: %v", err)
+	}
+
+	index := make(map[string]int)
+	for i, h := range header {
+		index[h] = i
+	}
+
+	for _, h := range []string{"SKU", "OfferTermCode", "RateCode", "TermType", "Unit", "PricePerUnit", "Product Family"} {
+		if _, ok := index[h]; !ok {
+			return PriceList{}, fmt.Errorf("column not found: %s", h)
+		}
+	}
+
+	for line := csvMeta + 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return list, nil
+		}
+
+		if err != nil {
+			return PriceList{}, fmt.Errorf("read line %d: %v", line, err)
+		}
+
+		get := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+
+			return record[i]
+		}
+
+		sku := get("SKU")
+		if _, ok := list.Products[sku]; !ok {
+			attr := make(map[string]string)
+			for i, h := range header {
+				// product attributes follow Product Family.
+				if i <= index["Product Family"] || i >= len(record) || len(record[i]) < 1 {
+					continue
+				}
+
+				attr[attribute(h)] = record[i]
+			}
+
+			list.Products[sku] = Product{
+				SKU:           sku,
+				ProductFamily: get("Product Family"),
+				Attributes:    attr,
+			}
+		}
+
+		termType := get("TermType")
+		if _, ok := list.Terms[termType]; !ok {
+			list.Terms[termType] = make(map[string]map[string]Term)
+		}
+
+		if _, ok := list.Terms[termType][sku]; !ok {
+			list.Terms[termType][sku] = make(map[string]Term)
+		}
+
+		key := fmt.Sprintf("%s.%s", sku, get("OfferTermCode"))
+		t, ok := list.Terms[termType][sku][key]
+		if !ok {
+			t = Term{
+				SKU:             sku,
+				OfferTermCode:   get("OfferTermCode"),
+				EffectiveDate:   get("EffectiveDate"),
+				PriceDimensions: make(map[string]PriceDimensions),
+				TermAttributes: TermAttributes{
+					LeaseContractLength: get("LeaseContractLength"),
+					OfferingClass:       get("OfferingClass"),
+					PurchaseOption:      get("PurchaseOption"),
+				},
+			}
+		}
+
+		t.PriceDimensions[get("RateCode")] = PriceDimensions{
+			RateCode:     get("RateCode"),
+			Description:  get("PriceDescription"),
+			BeginRange:   get("StartingRange"),
+			EndRange:     get("EndingRange"),
+			Unit:         get("Unit"),
+			PricePerUnit: PricePerUnit{USD: get("PricePerUnit")},
+		}
+
+		list.Terms[termType][sku][key] = t
+	}
+}
+
+// attribute returns the product attribute name of a CSV offer file column. "Operating System" is "operatingSystem".
+func attribute(column string) string {
+	if a, ok := csvAttribute[column]; ok {
+		return a
+	}
+
+	word := strings.Fields(column)
+	for i := range word {
+		if i == 0 {
+			word[i] = strings.ToLower(word[i][:1]) + word[i][1:]
+			continue
+		}
+
+		word[i] = strings.ToUpper(word[i][:1]) + word[i][1:]
+	}
+
+	return strings.Join(word, "")
+}
+
+// Import returns the prices of region in the offer file of service.
+// Products of other regions are ignored by their regionCode attribute.
+// A file of products without regionCode must be the offer file of a region, of a single location.
+func Import(file, service, region string) (map[string]Price, error) {
+	list, err := ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.OfferCode) > 0 && list.OfferCode != service {
+		return nil, fmt.Errorf("offer code: expected %s, actual %s", service, list.OfferCode)
+	}
+	list.OfferCode = service
+
+	location := make(map[string]bool)
+	for k, p := range list.Products {
+		r, ok := p.Attributes["regionCode"]
+		if !ok {
+			location[p.Attributes["location"]] = true
+			continue
+		}
+
+		if r != region {
+			delete(list.Products, k)
+		}
+	}
+
+	if len(location) > 1 {
+		return nil, fmt.Errorf("region code not found: products of %d locations. use the offer file of %s", len(location), region)
+	}
+
+	return fetch(region, list)
+}
+
+// Merge returns price with the prices of service replaced by imported.
+// The prices retired from the offer file are removed with the others of service.
+func Merge(price []Price, imported map[string]Price, service string) []Price {
+	out := make([]Price, 0)
+	for _, p := range price {
+		if p.Service() == service {
+			continue
+		}
+
+		out = append(out, p)
+	}
+
+	key := make([]string, 0)
+	for k := range imported {
+		key = append(key, k)
+	}
+	sort.Strings(key)
+
+	for _, k := range key {
+		out = append(out, imported[k])
+	}
+
+	return out
+}
@@ -0,0 +1,114 @@
+package pricing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+var offerCSV = `"FormatVersion","v1.0"
+"Disclaimer","This pricing list is for informational purposes only."
+"Publication Date","2019-07-30T01:21:38Z"
+"Version","20190730012138"
+"OfferCode","AmazonEC2"
+"SKU","OfferTermCode","RateCode","TermType","PriceDescription","EffectiveDate","StartingRange","EndingRange","Unit","PricePerUnit","Currency","LeaseContractLength","PurchaseOption","OfferingClass","Product Family","Instance Type","Operating System","Tenancy","usageType","Pre Installed S/W","Normalization Size Factor","vCPU","CapacityStatus","Region Code"
+"SKU0000000000001","JRTCKXETXF","SKU0000000000001.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.126 per On Demand Linux c4.large Instance Hour","2019-07-01","0","Inf","Hrs","0.1260000000","USD","","","","Compute Instance","c4.large","Linux","Shared","APN1-BoxUsage:c4.large","NA","4","2","Used","ap-northeast-1"
+"SKU0000000000001","6QCMYABX3D","SKU0000000000001.6QCMYABX3D.2TG2D8R56U","Reserved","Upfront Fee","2019-07-01","","","Quantity","738","USD","1yr","All Upfront","standard","Compute Instance","c4.large","Linux","Shared","APN1-BoxUsage:c4.large","NA","4","2","Used","ap-northeast-1"
+"SKU0000000000001","6QCMYABX3D","SKU0000000000001.6QCMYABX3D.6YS6EN2CT7","Reserved","Linux/UNIX (Amazon VPC), c4.large reserved instance applied","2019-07-01","0","Inf","Hrs","0.0000000000","USD","1yr","All Upfront","standard","Compute Instance","c4.large","Linux","Shared","APN1-BoxUsage:c4.large","NA","4","2","Used","ap-northeast-1"
+"SKU0000000000002","JRTCKXETXF","SKU0000000000002.JRTCKXETXF.6YS6EN2CT7","OnDemand","$0.1 per On Demand Linux c4.large Instance Hour","2019-07-01","0","Inf","Hrs","0.1000000000","USD","","","","Compute Instance","c4.large","Linux","Shared","USW2-BoxUsage:c4.large","NA","4","us-west-2"
+`
+
+func TestImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	fmt.Fprintf(gz, offer, "APN1")
+	gz.Close()
+
+	file := map[string][]byte{
+		"index.json.gz": buf.Bytes(),
+		"index.csv":     []byte(offerCSV),
+	}
+
+	for name, b := range file {
+		path := fmt.Sprintf("%s/%s", dir, name)
+		if err := ioutil.WriteFile(path, b, 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+
+		price, err := Import(path, "AmazonEC2", "ap-northeast-1")
+		if err != nil {
+			t.Fatalf("import %s: %v", name, err)
+		}
+
+		p, ok := price["SKU0000000000001.6QCMYABX3D"]
+		if len(price) != 1 || !ok {
+			t.Fatalf("%s: %v", name, price)
+		}
+
+		if p.Region != "ap-northeast-1" || p.UsageType != "APN1-BoxUsage:c4.large" || p.OnDemand != 0.126 || p.ReservedQuantity != 738 || p.LeaseContractLength != "1yr" || p.NormalizationSizeFactor != "4" || p.PreInstalled != "NA" || p.VCPU != "2" || p.CapacityStatus != "Used" {
+			t.Errorf("%s: %v", name, p)
+		}
+	}
+
+	if _, err := Import(fmt.Sprintf("%s/index.csv", dir), "AmazonRDS", "ap-northeast-1"); err == nil {
+		t.Errorf("expected offer code error")
+	}
+}
+
+func TestImportLocation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a whole service offer file without Region Code
+	header := strings.Index(offerCSV, "\"SKU\"")
+	body := strings.Replace(offerCSV[header:], ",\"Region Code\"", ",\"Location\"", 1)
+	body = strings.Replace(body, "\"ap-northeast-1\"", "\"Asia Pacific (Tokyo)\"", -1)
+	body = strings.Replace(body, "\"us-west-2\"", "\"US West (Oregon)\"", -1)
+
+	file := fmt.Sprintf("%s/index.csv", dir)
+	if err := ioutil.WriteFile(file, []byte(offerCSV[:header]+body), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := Import(file, "AmazonEC2", "ap-northeast-1"); err == nil || !strings.Contains(err.Error(), "region code not found") {
+		t.Errorf("expected region code not found: %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	price := []Price{
+		{SKU: "SKU0000000000001", OfferTermCode: "6QCMYABX3D", OnDemand: 0.1},
+		{SKU: "SKU0000000000002", OfferTermCode: "6QCMYABX3D", OnDemand: 0.2},
+		{SKU: "SKU0000000000004", OfferTermCode: "6QCMYABX3D", OnDemand: 0.4, DatabaseEngine: "MySQL"},
+	}
+
+	imported := map[string]Price{
+		"SKU0000000000001.6QCMYABX3D": {SKU: "SKU0000000000001", OfferTermCode: "6QCMYABX3D", OnDemand: 0.126},
+		"SKU0000000000003.6QCMYABX3D": {SKU: "SKU0000000000003", OfferTermCode: "6QCMYABX3D", OnDemand: 0.3},
+	}
+
+	merged := Merge(price, imported, "AmazonEC2")
+	expected := []float64{0.4, 0.126, 0.3}
+	if len(merged) != len(expected) {
+		t.Fatalf("merged: %v", merged)
+	}
+
+	for i := range expected {
+		if merged[i].OnDemand != expected[i] {
+			t.Errorf("expected: %v, actual: %v", expected[i], merged[i])
+		}
+	}
+}
@@ -2,6 +2,7 @@ package pricing
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -10,15 +11,33 @@ type Tuple struct {
 	Minimum Price
 }
 
-func Minimum(family map[string]Price, plist []Price) map[string]Tuple {
+// TupleKey returns the key of a Tuple in the result of Minimum.
+// Of license models, only Bring your own license is distinguished.
+func TupleKey(usageType, operatingSystem, preInstalled, licenseModel, cacheEngine, databaseEngine, databaseEdition string) string {
+	license := ""
+	if licenseModel == LicenseModelBYOL {
+		license = "BYOL"
+	}
+
+	return fmt.Sprintf("%s%s%s%s%s%s%s", usageType, operatingSystem, preInstalled, license, cacheEngine, databaseEngine, databaseEdition)
+}
+
+// Minimum returns each price of plist paired with the smallest price of its family by TupleKey.
+// plist is deduplicated with Dedupe beforehand.
+// Prices whose family has another offer term code are skipped and returned in an *InvalidError.
+func Minimum(family map[string]Price, plist []Price) (map[string]Tuple, error) {
+	plist, _ = Dedupe(plist)
+
 	smap := make(map[string]Tuple)
 	for i := range plist {
-		hash := fmt.Sprintf(
-			"%s%s%s%s",
+		hash := TupleKey(
 			plist[i].UsageType,
 			plist[i].OperatingSystem,
+			plist[i].PreInstalled,
+			plist[i].LicenseModel,
 			plist[i].CacheEngine,
 			plist[i].DatabaseEngine,
+			plist[i].DatabaseEdition,
 		)
 
 		if strings.LastIndex(plist[i].UsageType, ".") < 0 {
@@ -26,24 +45,15 @@ func Minimum(family map[string]Price, plist []Price) map[string]Tuple {
 			continue
 		}
 
-		fhash := fmt.Sprintf(
-			//			"%s%s%s%s%s%s%s%s%s%s%s%s",
-			"%s%s%s%s%s%s%s%s%s%s",
-			plist[i].UsageType[:strings.LastIndex(plist[i].UsageType, ".")],
-			plist[i].OperatingSystem,
-			plist[i].CacheEngine,
-			plist[i].DatabaseEngine,
-			//			plist[i].Operation,
-			//			plist[i].PreInstalled,
-			plist[i].Region,
-			plist[i].OfferingClass,
-			plist[i].Tenancy,
-			plist[i].PurchaseOption,
-			plist[i].LeaseContractLength,
-			plist[i].Version,
-		)
+		fhash := familyKey(plist[i])
 
-		smap[hash] = Tuple{plist[i], family[fhash]}
+		f, ok := family[fhash]
+		if !ok {
+			// the family has no valid price. Family reports them.
+			continue
+		}
+
+		smap[hash] = Tuple{plist[i], f}
 
 		//if strings.Contains(hash, "BoxUsage:c4.8x") && strings.Contains(hash, "Linux") {
 		//	if family[fhash].NormalizationSizeFactor == "8" {
@@ -54,11 +64,18 @@ func Minimum(family map[string]Price, plist []Price) map[string]Tuple {
 	}
 
 	// validation
-	for _, v := range smap {
+	invalid := make([]PriceError, 0)
+	for k, v := range smap {
 		if v.Price.OfferTermCode != v.Minimum.OfferTermCode {
-			panic("invalid OfferTermCode")
+			invalid = append(invalid, NewPriceError(v.Price, ErrOfferTermCode))
+			delete(smap, k)
 		}
 	}
 
-	return smap
+	if len(invalid) > 0 {
+		sort.Slice(invalid, func(i, j int) bool { return invalid[i].Error() < invalid[j].Error() })
+		return smap, &InvalidError{Invalid: invalid}
+	}
+
+	return smap, nil
 }
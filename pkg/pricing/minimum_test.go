@@ -11,9 +11,39 @@ func TestMinimum(t *testing.T) {
 		t.Errorf("desirialize pricing: %v", err)
 	}
 
-	family := Family(plist)
-	mini := Minimum(family, plist)
+	family, err := Family(plist)
+	if err != nil {
+		t.Errorf("family: %v", err)
+	}
+
+	mini, err := Minimum(family, plist)
+	if err != nil {
+		t.Errorf("minimum: %v", err)
+	}
+
 	for _, v := range mini {
 		fmt.Println(v)
 	}
 }
+
+func TestMinimumInvalid(t *testing.T) {
+	plist := []Price{
+		{SKU: "SKU1", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.large", NormalizationSizeFactor: "4"},
+		{SKU: "SKU2", OfferTermCode: "6QCMYABX3D", UsageType: "APN1-BoxUsage:c4.xlarge", NormalizationSizeFactor: "8"},
+	}
+
+	// the family of c4 with another offer term code.
+	family := map[string]Price{
+		"APN1-BoxUsage:c4": {SKU: "SKU1", OfferTermCode: "HU7G6KETJZ", UsageType: "APN1-BoxUsage:c4.large", NormalizationSizeFactor: "4"},
+	}
+
+	mini, err := Minimum(family, plist)
+	ierr, ok := err.(*InvalidError)
+	if !ok {
+		t.Fatalf("expected invalid error: %v", err)
+	}
+
+	if len(ierr.Invalid) != 2 || ierr.Invalid[0].Err != ErrOfferTermCode || len(mini) != 0 {
+		t.Errorf("invalid: %v, minimum: %v", ierr.Invalid, mini)
+	}
+}
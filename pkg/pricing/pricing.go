@@ -7,7 +7,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strconv"
+	neturl "net/url"
+	"sort"
 	"strings"
 )
 
@@ -16,13 +17,49 @@ var URL = []string{
 	Database,
 	Cache,
 	Redshift,
+	OpenSearch,
+	DynamoDB,
+	MemoryDB,
 }
 
 var BaseURL = "https://pricing.us-east-1.amazonaws.com"
-var Compute = fmt.Sprintf("%s%s", BaseURL, "/offers/v1.0/aws/AmazonEC2/current/region_index.json")
-var Database = fmt.Sprintf("%s%s", BaseURL, "/offers/v1.0/aws/AmazonRDS/current/region_index.json")
-var Cache = fmt.Sprintf("%s%s", BaseURL, "/offers/v1.0/aws/AmazonElastiCache/current/region_index.json")
-var Redshift = fmt.Sprintf("%s%s", BaseURL, "/offers/v1.0/aws/AmazonRedshift/current/region_index.json")
+var Compute = IndexURL(BaseURL, "AmazonEC2")
+var Database = IndexURL(BaseURL, "AmazonRDS")
+var Cache = IndexURL(BaseURL, "AmazonElastiCache")
+var Redshift = IndexURL(BaseURL, "AmazonRedshift")
+var OpenSearch = IndexURL(BaseURL, "AmazonES")
+var DynamoDB = IndexURL(BaseURL, "AmazonDynamoDB")
+var MemoryDB = IndexURL(BaseURL, "AmazonMemoryDB")
+
+// ServiceCode is the services of URL.
+var ServiceCode = []string{
+	"AmazonEC2",
+	"AmazonRDS",
+	"AmazonElastiCache",
+	"AmazonRedshift",
+	"AmazonES",
+	"AmazonDynamoDB",
+	"AmazonMemoryDB",
+}
+
+// AllRegion is the region name standing for every region.
+const AllRegion = "all"
+
+// IndexURL returns the region index url of service served by endpoint.
+func IndexURL(endpoint, service string) string {
+	return fmt.Sprintf("%s/offers/v1.0/aws/%s/current/region_index.json", strings.TrimSuffix(endpoint, "/"), service)
+}
+
+// URLList returns the region index urls of ServiceCode served by endpoint,
+// a mirror of the price list api or BaseURL.
+func URLList(endpoint string) []string {
+	out := make([]string, 0)
+	for _, s := range ServiceCode {
+		out = append(out, IndexURL(endpoint, s))
+	}
+
+	return out
+}
 
 type InputPrice struct {
 	FormatVersion   string               `json:"formatVersion"`
@@ -97,14 +134,25 @@ type Price struct {
 	Operation               string  // compute
 	OperatingSystem         string  // compute: Windows, Linux, SUSE, RHEL
 	CacheEngine             string  // cache
-	DatabaseEngine          string  // database
+	DatabaseEngine          string  // database: MySQL, Aurora MySQL, Oracle, SQL Server
+	DatabaseEdition         string  // database: Standard Two, Enterprise, Web
 	OfferingClass           string  // compute, database
 	NormalizationSizeFactor string  // compute, database
+	VCPU                    string  // compute, database, cache
+	Memory                  string  // compute, database, cache: 3.75 GiB
+	InstanceFamily          string  // compute, database: Compute optimized
+	PhysicalProcessor       string  // compute, database: Intel Xeon Platinum 8124M
+	NetworkPerformance      string  // compute, database, cache: Moderate, Up to 10 Gigabit
+	CurrentGeneration       string  // compute, database, cache: Yes, No
+	DeploymentOption        string  // database: Single-AZ, Multi-AZ
+	LicenseModel            string  // compute, database: No License required, Bring your own license
+	CapacityStatus          string  // compute: Used, UnusedCapacityReservation, AllocatedCapacityReservation
+	Location                string  // common: Asia Pacific (Tokyo)
 }
 
 func (p Price) Hash() string {
 	s := fmt.Sprintf(
-		"%s%s%s%s%s%s%s%s%s",
+		"%s%s%s%s%s%s%s%s%s%s",
 		strings.Split(p.UsageType, ".")[0],
 		p.LeaseContractLength,
 		p.PurchaseOption,
@@ -113,30 +161,64 @@ func (p Price) Hash() string {
 		p.OperatingSystem,
 		p.CacheEngine,
 		p.DatabaseEngine,
+		p.DatabaseEdition,
 		p.OfferingClass,
 	)
 
-	val, err := json.Marshal(s)
-	if err != nil {
-		panic(err)
-	}
+	// a string is always marshaled.
+	val, _ := json.Marshal(s)
 
 	sha := sha256.Sum256(val)
 	hash := hex.EncodeToString(sha[:])
 	return hash
 }
 
+// Service returns the service code (AmazonEC2) of p by its usage type and engine.
+func (p Price) Service() string {
+	if strings.Contains(p.UsageType, "Node:") {
+		return "AmazonRedshift"
+	}
+
+	if strings.Contains(p.UsageType, "ESInstance:") {
+		return "AmazonES"
+	}
+
+	if strings.Contains(p.UsageType, "CapacityUnit-Hrs") {
+		return "AmazonDynamoDB"
+	}
+
+	if strings.Contains(p.UsageType, "NodeUsage:db.") {
+		return "AmazonMemoryDB"
+	}
+
+	if len(p.DatabaseEngine) > 0 {
+		return "AmazonRDS"
+	}
+
+	if len(p.CacheEngine) > 0 {
+		return "AmazonElastiCache"
+	}
+
+	return "AmazonEC2"
+}
+
 func (p Price) String() string {
-	return p.JSON()
+	s, err := p.JSON()
+	if err != nil {
+		return fmt.Sprintf("%s.%s (%s): %v", p.SKU, p.OfferTermCode, p.UsageType, err)
+	}
+
+	return s
 }
 
-func (p Price) JSON() string {
+// JSON returns p in json. It fails when a rate is NaN or Inf.
+func (p Price) JSON() (string, error) {
 	bytes, err := json.Marshal(p)
 	if err != nil {
-		panic(err)
+		return "", fmt.Errorf("marshal %s.%s: %v", p.SKU, p.OfferTermCode, err)
 	}
 
-	return string(bytes)
+	return string(bytes), nil
 }
 
 func (p Price) DiscountRate() float64 {
@@ -185,58 +267,118 @@ func Fetch(url, region string) (map[string]Price, error) {
 }
 
 func FetchWithClient(url, region string, client *http.Client) (map[string]Price, error) {
+	input, err := FetchIndex(url, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return FetchRegion(url, input, region, client)
+}
+
+// FetchIndex returns the region index of the service at url.
+func FetchIndex(url string, client *http.Client) (InputPrice, error) {
 	var input InputPrice
-	{
-		resp, err := client.Get(url)
-		if err != nil {
-			return nil, fmt.Errorf("get %s: %v", url, err)
-		}
+	if err := get(client, url, &input); err != nil {
+		return InputPrice{}, err
+	}
 
-		buf, err := ioutil.ReadAll(resp.Body)
+	return input, nil
+}
+
+// FetchRegionList returns the regions listed in the region index of any service in url.
+func FetchRegionList(url []string, client *http.Client) ([]string, error) {
+	region := make(map[string]bool)
+	for _, u := range url {
+		input, err := FetchIndex(u, client)
 		if err != nil {
-			return nil, fmt.Errorf("read body: %v", err)
+			return nil, fmt.Errorf("fetch index %s: %v", Service(u), err)
 		}
 
-		if err := json.Unmarshal(buf, &input); err != nil {
-			return nil, fmt.Errorf("unmarshal: %v", err)
+		for r := range input.Regions {
+			region[r] = true
 		}
 	}
 
+	out := make([]string, 0)
+	for r := range region {
+		out = append(out, r)
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+// FetchRegion returns the prices of region in the offer file listed in input, the region index fetched from url.
+// It returns no prices when the service is not offered in region.
+func FetchRegion(url string, input InputPrice, region string, client *http.Client) (map[string]Price, error) {
+	r, ok := input.Regions[region]
+	if !ok {
+		return make(map[string]Price), nil
+	}
+
+	base, err := neturl.Parse(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", url, err)
+	}
+
+	ref, err := neturl.Parse(r.CurrentVersionUrl)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %v", r.CurrentVersionUrl, err)
+	}
+
 	var list PriceList
-	{
-		url := fmt.Sprintf("%s%s", BaseURL, input.Regions[region].CurrentVersionUrl)
-		resp, err := client.Get(url)
-		if err != nil {
-			return nil, fmt.Errorf("get %s: %v", url, err)
-		}
+	if err := get(client, base.ResolveReference(ref).String(), &list); err != nil {
+		return nil, err
+	}
 
-		buf, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("read body: %v", err)
-		}
+	return fetch(region, list)
+}
 
-		if err := json.Unmarshal(buf, &list); err != nil {
-			return nil, fmt.Errorf("unmarshal: %v", err)
+// Service returns the service code (AmazonEC2) of a region index url.
+func Service(url string) string {
+	s := strings.Split(url, "/")
+	for i := range s {
+		if s[i] == "aws" && i+1 < len(s) {
+			return s[i+1]
 		}
 	}
 
-	return fetch(region, list)
+	return url
 }
 
+func get(client *http.Client, url string, v interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("get %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: %s", url, resp.Status)
+	}
+
+	buf, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read body: %v", err)
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("unmarshal: %v", err)
+	}
+
+	return nil
+}
+
+// fetch returns the prices of region in list with the parser of its offer code.
 func fetch(region string, list PriceList) (map[string]Price, error) {
+	parse := parserOf(list.OfferCode)
+
 	p := make(map[string]Price)
+	sku := make(map[string][]string)
 	{
 		for _, t := range list.Terms["Reserved"] {
 			for k, v := range t {
-				var q, h float64
-				for _, vv := range v.PriceDimensions {
-					if vv.Unit == "Quantity" {
-						q, _ = strconv.ParseFloat(vv.PricePerUnit.USD, 64)
-					}
-					if vv.Unit == "Hrs" {
-						h, _ = strconv.ParseFloat(vv.PricePerUnit.USD, 64)
-					}
-				}
+				q, h := parse.Reserved(v)
 
 				// k is SKU.OfferingTermCode. it is unique.
 				p[k] = Price{
@@ -249,30 +391,17 @@ func fetch(region string, list PriceList) (map[string]Price, error) {
 					ReservedHrs:         h,
 					ReservedQuantity:    q,
 				}
+				sku[v.SKU] = append(sku[v.SKU], k)
 			}
 		}
 
 		for _, t := range list.Terms["OnDemand"] {
-			for k, v := range t { // 1
-				for kk, pp := range p {
-					if !strings.HasPrefix(k, pp.SKU) {
-						continue
-					}
-
-					for _, vv := range v.PriceDimensions { // 1
-						hrs, _ := strconv.ParseFloat(vv.PricePerUnit.USD, 64)
-						p[kk] = Price{
-							Version:             p[kk].Version,
-							SKU:                 p[kk].SKU,
-							OfferTermCode:       p[kk].OfferTermCode,
-							LeaseContractLength: p[kk].LeaseContractLength,
-							PurchaseOption:      p[kk].PurchaseOption,
-							OfferingClass:       p[kk].OfferingClass,
-							ReservedHrs:         p[kk].ReservedHrs,
-							ReservedQuantity:    p[kk].ReservedQuantity,
-							OnDemand:            hrs,
-						}
-					}
+			for _, v := range t { // 1
+				hrs := parse.OnDemand(v)
+				for _, kk := range sku[v.SKU] {
+					price := p[kk]
+					price.OnDemand = hrs
+					p[kk] = price
 				}
 			}
 		}
@@ -280,11 +409,8 @@ func fetch(region string, list PriceList) (map[string]Price, error) {
 
 	out := make(map[string]Price)
 	for _, pp := range list.Products {
-		for k, v := range p {
-			if !strings.HasPrefix(k, pp.SKU) {
-				continue
-			}
-
+		for _, k := range sku[pp.SKU] {
+			v := p[k]
 			out[k] = Price{
 				Version:                 v.Version,
 				SKU:                     v.SKU,
@@ -298,6 +424,7 @@ func fetch(region string, list PriceList) (map[string]Price, error) {
 				Operation:               pp.Attributes["operation"],
 				CacheEngine:             pp.Attributes["cacheEngine"],
 				DatabaseEngine:          pp.Attributes["databaseEngine"],
+				DatabaseEdition:         pp.Attributes["databaseEdition"],
 				LeaseContractLength:     v.LeaseContractLength,
 				PurchaseOption:          v.PurchaseOption,
 				OfferingClass:           v.OfferingClass,
@@ -305,6 +432,20 @@ func fetch(region string, list PriceList) (map[string]Price, error) {
 				ReservedQuantity:        v.ReservedQuantity,
 				ReservedHrs:             v.ReservedHrs,
 				NormalizationSizeFactor: pp.Attributes["normalizationSizeFactor"],
+				VCPU:                    pp.Attributes["vcpu"],
+				Memory:                  pp.Attributes["memory"],
+				InstanceFamily:          pp.Attributes["instanceFamily"],
+				PhysicalProcessor:       pp.Attributes["physicalProcessor"],
+				NetworkPerformance:      pp.Attributes["networkPerformance"],
+				CurrentGeneration:       pp.Attributes["currentGeneration"],
+				DeploymentOption:        pp.Attributes["deploymentOption"],
+				LicenseModel:            pp.Attributes["licenseModel"],
+				CapacityStatus:          pp.Attributes["capacitystatus"],
+				Location:                pp.Attributes["location"],
+			}
+
+			if parse.Price != nil {
+				out[k] = parse.Price(out[k])
 			}
 		}
 	}
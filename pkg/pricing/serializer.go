@@ -6,25 +6,38 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+
+	"github.com/itsubaki/hermes/pkg/cache"
 )
 
+// SchemaVersion is the version of []Price written by Serialize.
+const SchemaVersion = 1
+
 func Serialize(dir, region string, price []Price) error {
+	return SerializeWithOption(dir, region, price, cache.Option{})
+}
+
+func SerializeWithOption(dir, region string, price []Price, opt cache.Option) error {
 	path := fmt.Sprintf("%s/pricing", dir)
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		os.MkdirAll(path, os.ModePerm)
 	}
 
 	file := fmt.Sprintf("%s/%s.out", path, region)
-	if _, err := os.Stat(file); !os.IsNotExist(err) {
+	if _, err := os.Stat(file); !os.IsNotExist(err) && !opt.Overwrite {
 		return nil
 	}
 
-	bytes, err := json.Marshal(price)
+	if len(opt.Source) < 1 {
+		opt.Source = BaseURL
+	}
+
+	bytes, err := cache.Encode(SchemaVersion, price, opt)
 	if err != nil {
-		return fmt.Errorf("marshal: %v", err)
+		return fmt.Errorf("encode: %v", err)
 	}
 
-	if err := ioutil.WriteFile(file, bytes, os.ModePerm); err != nil {
+	if err := cache.WriteFile(file, bytes); err != nil {
 		return fmt.Errorf("write file: %v", err)
 	}
 
@@ -44,9 +57,9 @@ func Deserialize(dir string, region []string) ([]Price, error) {
 			return []Price{}, fmt.Errorf("read %s: %v", file, err)
 		}
 
-		var p []Price
-		if err := json.Unmarshal(read, &p); err != nil {
-			return []Price{}, fmt.Errorf("unmarshal: %v", err)
+		p, err := Decode(read)
+		if err != nil {
+			return []Price{}, fmt.Errorf("decode %s: %v", file, err)
 		}
 
 		price = append(price, p...)
@@ -60,3 +73,28 @@ func Deserialize(dir string, region []string) ([]Price, error) {
 
 	return price, nil
 }
+
+// Decode returns the prices of a cache file, migrating older schema versions.
+func Decode(b []byte) ([]Price, error) {
+	h, data, err := cache.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.Check(h, SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	var p []Price
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	return migrate(h.SchemaVersion, p), nil
+}
+
+// migrate upgrades prices read from schema version v to SchemaVersion.
+func migrate(v int, price []Price) []Price {
+	// 0 -> 1: bare json array without header. Price is unchanged.
+	return price
+}
@@ -2,11 +2,56 @@ package pricing
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
+
+	"github.com/itsubaki/hermes/pkg/cache"
 )
 
 func TestSerialize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	price := []Price{
+		{
+			Version:         "20190730012138",
+			Region:          "ap-northeast-1",
+			UsageType:       "APN1-BoxUsage:c4.large",
+			OperatingSystem: "Linux",
+			OnDemand:        0.126,
+		},
+	}
+
+	if err := SerializeWithOption(dir, "ap-northeast-1", price, cache.Option{Compress: true}); err != nil {
+		t.Errorf("serialize: %v", err)
+	}
+
+	legacy := `[{"Version":"20190730012138","Region":"us-west-2"}]`
+	if err := ioutil.WriteFile(fmt.Sprintf("%s/pricing/us-west-2.out", dir), []byte(legacy), 0644); err != nil {
+		t.Errorf("write file: %v", err)
+	}
 
+	p, err := Deserialize(dir, []string{"ap-northeast-1", "us-west-2"})
+	if err != nil {
+		t.Errorf("deserialize: %v", err)
+	}
+
+	if len(p) != 2 || p[0] != price[0] || p[1].Region != "us-west-2" {
+		t.Errorf("actual: %v", p)
+	}
+
+	future := `{"schema_version":99,"hermes_version":"future","data":[]}`
+	if err := ioutil.WriteFile(fmt.Sprintf("%s/pricing/eu-west-1.out", dir), []byte(future), 0644); err != nil {
+		t.Errorf("write file: %v", err)
+	}
+
+	if _, err := Deserialize(dir, []string{"eu-west-1"}); err == nil {
+		t.Errorf("expected error")
+	}
 }
 
 func TestDeserialize(t *testing.T) {
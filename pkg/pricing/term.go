@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"math"
+	"strconv"
+)
+
+// DynamoDBReservedUnits is the capacity units of a DynamoDB reserved capacity.
+// The reserved rates in the offer file are of a reserved capacity.
+const DynamoDBReservedUnits = 100
+
+// parser reads the rates of the terms and the attributes of the products of an offer file.
+type parser struct {
+	Reserved func(t Term) (quantity, hrs float64) // the upfront fee and the hourly rate of a reserved term
+	OnDemand func(t Term) float64                 // the hourly rate of an on-demand term
+	Price    func(p Price) Price                  // the price of a product. nil keeps it as it is
+}
+
+var defaultParser = parser{Reserved: reservedRate, OnDemand: onDemandRate}
+
+// parsers are the parsers of the offer files by offer code. The others are read with defaultParser.
+var parsers = map[string]parser{
+	"AmazonDynamoDB": {Reserved: dynamoDBReservedRate, OnDemand: paidOnDemandRate},
+	"AmazonMemoryDB": {Reserved: reservedRate, OnDemand: paidOnDemandRate, Price: memoryDBPrice},
+}
+
+// parserOf returns the parser of the offer file of offerCode.
+func parserOf(offerCode string) parser {
+	if p, ok := parsers[offerCode]; ok {
+		return p
+	}
+
+	return defaultParser
+}
+
+// reservedRate returns the upfront fee (Quantity) and the hourly rate (Hrs) of t.
+func reservedRate(t Term) (quantity, hrs float64) {
+	for _, d := range t.PriceDimensions {
+		if d.Unit == "Quantity" {
+			quantity, _ = strconv.ParseFloat(d.PricePerUnit.USD, 64)
+		}
+
+		if d.Unit == "Hrs" {
+			hrs, _ = strconv.ParseFloat(d.PricePerUnit.USD, 64)
+		}
+	}
+
+	return quantity, hrs
+}
+
+// onDemandRate returns the rate of the price dimension of t.
+func onDemandRate(t Term) float64 {
+	var hrs float64
+	for _, d := range t.PriceDimensions {
+		hrs, _ = strconv.ParseFloat(d.PricePerUnit.USD, 64)
+	}
+
+	return hrs
+}
+
+// paidOnDemandRate returns the rate of the first tier of t with a price.
+// The free tier (DynamoDB: the first 25 capacity units) is ignored.
+func paidOnDemandRate(t Term) float64 {
+	var hrs float64
+	begin := math.Inf(1)
+	for _, d := range t.PriceDimensions {
+		rate, err := strconv.ParseFloat(d.PricePerUnit.USD, 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+
+		b, err := strconv.ParseFloat(d.BeginRange, 64)
+		if err != nil {
+			b = 0
+		}
+
+		if b < begin {
+			hrs, begin = rate, b
+		}
+	}
+
+	return hrs
+}
+
+// dynamoDBReservedRate returns the upfront fee and the hourly rate of t per capacity unit.
+// The hourly rate of a reserved capacity is in capacity unit hours (ReadCapacityUnit-Hrs), not Hrs.
+func dynamoDBReservedRate(t Term) (quantity, hrs float64) {
+	for _, d := range t.PriceDimensions {
+		rate, err := strconv.ParseFloat(d.PricePerUnit.USD, 64)
+		if err != nil {
+			continue
+		}
+
+		if d.Unit == "Quantity" {
+			quantity = rate
+			continue
+		}
+
+		hrs = rate
+	}
+
+	return quantity / DynamoDBReservedUnits, hrs / DynamoDBReservedUnits
+}
+
+// memoryDBPrice returns p without the cache engine.
+// MemoryDB usage is fetched by usage type only, and it is matched to the price by usage type.
+func memoryDBPrice(p Price) Price {
+	p.CacheEngine = ""
+	return p
+}
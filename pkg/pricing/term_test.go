@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+var dynamoDBOffer = `{
+  "offerCode": "AmazonDynamoDB",
+  "version": "20190730012138",
+  "products": {
+    "SKU0000000000001": {
+      "sku": "SKU0000000000001",
+      "productFamily": "Provisioned IOPS",
+      "attributes": {"servicecode": "AmazonDynamoDB", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "group": "DDB-ReadUnits", "groupDescription": "Provisioned read units", "usagetype": "APN1-ReadCapacityUnit-Hrs", "operation": "CommittedThroughput"}
+    }
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU0000000000001": {
+        "SKU0000000000001.JRTCKXETXF": {
+          "sku": "SKU0000000000001",
+          "offerTermCode": "JRTCKXETXF",
+          "priceDimensions": {
+            "SKU0000000000001.JRTCKXETXF.3MDCYZUWHJ": {"description": "$0.00 per hour for 25 units of read capacity for a month (free tier)", "beginRange": "0", "endRange": "18600", "unit": "ReadCapacityUnit-Hrs", "pricePerUnit": {"USD": "0.0000000000"}},
+            "SKU0000000000001.JRTCKXETXF.6YS6EN2CT7": {"description": "$0.0001484 per hour for units of read capacity beyond the free tier", "beginRange": "18600", "endRange": "Inf", "unit": "ReadCapacityUnit-Hrs", "pricePerUnit": {"USD": "0.0001484000"}}
+          }
+        }
+      }
+    },
+    "Reserved": {
+      "SKU0000000000001": {
+        "SKU0000000000001.MZU6U2429S": {
+          "sku": "SKU0000000000001",
+          "offerTermCode": "MZU6U2429S",
+          "priceDimensions": {
+            "SKU0000000000001.MZU6U2429S.2TG2D8R56U": {"description": "Upfront Fee", "unit": "Quantity", "pricePerUnit": {"USD": "34.8"}},
+            "SKU0000000000001.MZU6U2429S.6YS6EN2CT7": {"description": "$0.0068 per hour for 100 units of read capacity", "beginRange": "0", "endRange": "Inf", "unit": "ReadCapacityUnit-Hrs", "pricePerUnit": {"USD": "0.0068000000"}}
+          },
+          "termAttributes": {"LeaseContractLength": "1yr", "PurchaseOption": "Heavy Utilization"}
+        }
+      }
+    }
+  }
+}`
+
+var memoryDBOffer = `{
+  "offerCode": "AmazonMemoryDB",
+  "version": "20190730012138",
+  "products": {
+    "SKU0000000000002": {
+      "sku": "SKU0000000000002",
+      "productFamily": "MemoryDB Node",
+      "attributes": {"servicecode": "AmazonMemoryDB", "location": "Asia Pacific (Tokyo)", "regionCode": "ap-northeast-1", "instanceType": "db.r6g.large", "cacheEngine": "Redis", "vcpu": "2", "memory": "13.07 GiB", "usagetype": "APN1-NodeUsage:db.r6g.large", "operation": "CreateCluster"}
+    }
+  },
+  "terms": {
+    "OnDemand": {
+      "SKU0000000000002": {
+        "SKU0000000000002.JRTCKXETXF": {
+          "sku": "SKU0000000000002",
+          "offerTermCode": "JRTCKXETXF",
+          "priceDimensions": {
+            "SKU0000000000002.JRTCKXETXF.6YS6EN2CT7": {"description": "$0.371 per hour for db.r6g.large", "beginRange": "0", "endRange": "Inf", "unit": "Hrs", "pricePerUnit": {"USD": "0.3710000000"}}
+          }
+        }
+      }
+    },
+    "Reserved": {
+      "SKU0000000000002": {
+        "SKU0000000000002.VJWZNREJX2": {
+          "sku": "SKU0000000000002",
+          "offerTermCode": "VJWZNREJX2",
+          "priceDimensions": {
+            "SKU0000000000002.VJWZNREJX2.2TG2D8R56U": {"description": "Upfront Fee", "unit": "Quantity", "pricePerUnit": {"USD": "1170"}},
+            "SKU0000000000002.VJWZNREJX2.6YS6EN2CT7": {"description": "$0.134 per hour for db.r6g.large", "beginRange": "0", "endRange": "Inf", "unit": "Hrs", "pricePerUnit": {"USD": "0.1340000000"}}
+          },
+          "termAttributes": {"LeaseContractLength": "1yr", "PurchaseOption": "Partial Upfront"}
+        }
+      }
+    }
+  }
+}`
+
+func TestImportTerm(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		Service  string
+		Offer    string
+		Key      string
+		Expected Price
+	}{
+		{
+			"AmazonDynamoDB",
+			dynamoDBOffer,
+			"SKU0000000000001.MZU6U2429S",
+			Price{UsageType: "APN1-ReadCapacityUnit-Hrs", OnDemand: 0.0001484, ReservedQuantity: 0.348, ReservedHrs: 0.000068, PurchaseOption: "Heavy Utilization"},
+		},
+		{
+			"AmazonMemoryDB",
+			memoryDBOffer,
+			"SKU0000000000002.VJWZNREJX2",
+			Price{UsageType: "APN1-NodeUsage:db.r6g.large", OnDemand: 0.371, ReservedQuantity: 1170, ReservedHrs: 0.134, PurchaseOption: "Partial Upfront"},
+		},
+	}
+
+	equal := func(a, b float64) bool { return math.Abs(a-b) < 1e-12 }
+	for _, c := range cases {
+		file := fmt.Sprintf("%s/%s.json", dir, c.Service)
+		if err := ioutil.WriteFile(file, []byte(c.Offer), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+
+		price, err := Import(file, c.Service, "ap-northeast-1")
+		if err != nil {
+			t.Fatalf("import %s: %v", c.Service, err)
+		}
+
+		p, ok := price[c.Key]
+		if len(price) != 1 || !ok {
+			t.Fatalf("%s: %v", c.Service, price)
+		}
+
+		e := c.Expected
+		if p.UsageType != e.UsageType || p.PurchaseOption != e.PurchaseOption || !equal(p.OnDemand, e.OnDemand) || !equal(p.ReservedQuantity, e.ReservedQuantity) || !equal(p.ReservedHrs, e.ReservedHrs) {
+			t.Errorf("%s: %v", c.Service, p)
+		}
+
+		if len(p.CacheEngine) > 0 || p.Service() != c.Service {
+			t.Errorf("%s: %v", c.Service, p)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+// File stores pricing and usage as json files in {dir}/pricing/{region}.out and {dir}/usage/{YYYY-MM}.out.
+type File struct {
+	Dir    string
+	Option cache.Option
+}
+
+func NewFile(dir string) *File {
+	return NewFileWithOption(dir, cache.Option{})
+}
+
+func NewFileWithOption(dir string, opt cache.Option) *File {
+	return &File{Dir: dir, Option: opt}
+}
+
+func (f *File) ExistsPricing(region string) (bool, error) {
+	return exists(fmt.Sprintf("%s/pricing/%s.out", f.Dir, region))
+}
+
+// ListPricing returns the regions in {dir}/pricing.
+func (f *File) ListPricing() ([]string, error) {
+	file, err := filepath.Glob(fmt.Sprintf("%s/pricing/*.out", f.Dir))
+	if err != nil {
+		return nil, fmt.Errorf("glob: %v", err)
+	}
+
+	out := make([]string, 0)
+	for _, p := range file {
+		out = append(out, strings.TrimSuffix(filepath.Base(p), ".out"))
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+// WritePricing writes price and the versions of a snapshot of it to {dir}/pricing/history/{region}.
+// The prices of the versions replaced by price are archived there.
+func (f *File) WritePricing(region string, price []pricing.Price) error {
+	ok, err := f.ExistsPricing(region)
+	if err != nil {
+		return err
+	}
+
+	if ok && !f.Option.Overwrite {
+		return nil
+	}
+
+	if ok {
+		// a corrupted cache file is replaced without archive.
+		if cached, err := pricing.Deserialize(f.Dir, []string{region}); err == nil {
+			if err := pricing.ArchiveVersion(f.Dir, region, cached, pricing.Version(price), f.Option); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := pricing.SerializeWithOption(f.Dir, region, price, f.Option); err != nil {
+		return err
+	}
+
+	return pricing.SerializeSnapshot(f.Dir, region, pricing.NewSnapshot(time.Now()), pricing.Version(price), f.Option)
+}
+
+func (f *File) ReadPricing(region []string) ([]pricing.Price, error) {
+	return pricing.Deserialize(f.Dir, region)
+}
+
+func (f *File) ListPricingSnapshot(region string) ([]string, error) {
+	return pricing.ListSnapshot(f.Dir, region)
+}
+
+func (f *File) ReadPricingSnapshot(region, snapshot string) ([]pricing.Price, error) {
+	return pricing.DeserializeSnapshot(f.Dir, region, snapshot)
+}
+
+func (f *File) ReadPricingSnapshotVersion(region, snapshot string) ([]string, error) {
+	return pricing.SnapshotVersion(f.Dir, region, snapshot)
+}
+
+func (f *File) ExistsUsage(date usage.Date) (bool, error) {
+	return exists(fmt.Sprintf("%s/usage/%s.out", f.Dir, date.YYYYMM()))
+}
+
+func (f *File) WriteUsage(date usage.Date, quantity []usage.Quantity) error {
+	return usage.SerializeWithOption(f.Dir, date, quantity, f.Option)
+}
+
+func (f *File) ReadUsage(date []usage.Date) ([]usage.Quantity, error) {
+	return usage.Deserialize(f.Dir, date)
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+func exists(file string) (bool, error) {
+	_, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("stat %s: %v", file, err)
+	}
+
+	return true, nil
+}
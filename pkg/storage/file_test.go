@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testStorage(t, NewFile(dir))
+}
+
+func TestFileHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := NewFileWithOption(dir, cache.Option{Overwrite: true})
+	v1 := []pricing.Price{{Version: "20190730012138", SKU: "SKU1", OfferTermCode: "6QCMYABX3D", OnDemand: 0.126}}
+	v2 := []pricing.Price{{Version: "20190815012138", SKU: "SKU1", OfferTermCode: "6QCMYABX3D", OnDemand: 0.12}}
+
+	for _, p := range [][]pricing.Price{v1, v2} {
+		if err := s.WritePricing("ap-northeast-1", p); err != nil {
+			t.Fatalf("write pricing: %v", err)
+		}
+
+		// snapshots are named by the second
+		time.Sleep(time.Second)
+	}
+
+	snapshot, err := s.ListPricingSnapshot("ap-northeast-1")
+	if err != nil || len(snapshot) != 2 {
+		t.Fatalf("snapshot: %v, %v", snapshot, err)
+	}
+
+	for i, expected := range [][]pricing.Price{v1, v2} {
+		version, err := s.ReadPricingSnapshotVersion("ap-northeast-1", snapshot[i])
+		if err != nil || len(version) != 1 || version[0] != expected[0].Version {
+			t.Errorf("version: %v, %v", version, err)
+		}
+
+		p, err := s.ReadPricingSnapshot("ap-northeast-1", snapshot[i])
+		if err != nil || len(p) != 1 || p[0] != expected[0] {
+			t.Errorf("expected: %v, actual: %v, %v", expected, p, err)
+		}
+	}
+}
+
+func testStorage(t *testing.T, s Storage) {
+	price := []pricing.Price{
+		{
+			Version:                 "20190730012138",
+			SKU:                     "7MYWT7Y96UT3NJ2D",
+			OfferTermCode:           "4NA7Y494T4",
+			Region:                  "ap-northeast-1",
+			InstanceType:            "c4.large",
+			UsageType:               "APN1-BoxUsage:c4.large",
+			Tenancy:                 "Shared",
+			PreInstalled:            "NA",
+			OperatingSystem:         "Linux",
+			OfferingClass:           "standard",
+			LeaseContractLength:     "1yr",
+			PurchaseOption:          "All Upfront",
+			OnDemand:                0.126,
+			ReservedQuantity:        738,
+			ReservedHrs:             0,
+			NormalizationSizeFactor: "4",
+		},
+	}
+
+	date := usage.Date{Start: "2019-08-01", End: "2019-09-01"}
+	quantity := []usage.Quantity{
+		{
+			AccountID:    "123456789012",
+			Description:  "example",
+			Region:       "ap-northeast-1",
+			UsageType:    "APN1-BoxUsage:c4.large",
+			Platform:     "Linux/UNIX",
+			Date:         "2019-08",
+			InstanceHour: 744,
+			InstanceNum:  1,
+		},
+	}
+
+	if ok, err := s.ExistsPricing("ap-northeast-1"); err != nil || ok {
+		t.Errorf("exists pricing: %v, %v", ok, err)
+	}
+
+	if err := s.WritePricing("ap-northeast-1", price); err != nil {
+		t.Errorf("write pricing: %v", err)
+	}
+
+	if ok, err := s.ExistsPricing("ap-northeast-1"); err != nil || !ok {
+		t.Errorf("exists pricing: %v, %v", ok, err)
+	}
+
+	p, err := s.ReadPricing([]string{"ap-northeast-1"})
+	if err != nil {
+		t.Errorf("read pricing: %v", err)
+	}
+
+	if len(p) != 1 || p[0] != price[0] {
+		t.Errorf("expected: %v, actual: %v", price, p)
+	}
+
+	if _, err := s.ReadPricing([]string{"us-west-2"}); err == nil {
+		t.Errorf("expected error")
+	}
+
+	region, err := Region(s, []string{"all"})
+	if err != nil {
+		t.Errorf("region: %v", err)
+	}
+
+	if len(region) != 1 || region[0] != "ap-northeast-1" {
+		t.Errorf("region: %v", region)
+	}
+
+	if ok, err := s.ExistsUsage(date); err != nil || ok {
+		t.Errorf("exists usage: %v, %v", ok, err)
+	}
+
+	if err := s.WriteUsage(date, quantity); err != nil {
+		t.Errorf("write usage: %v", err)
+	}
+
+	if ok, err := s.ExistsUsage(date); err != nil || !ok {
+		t.Errorf("exists usage: %v, %v", ok, err)
+	}
+
+	q, err := s.ReadUsage([]usage.Date{date})
+	if err != nil {
+		t.Errorf("read usage: %v", err)
+	}
+
+	if len(q) != 1 || q[0] != quantity[0] {
+		t.Errorf("expected: %v, actual: %v", quantity, q)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+}
@@ -0,0 +1,881 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+	_ "modernc.org/sqlite"
+)
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS entry (
+		kind       TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		PRIMARY KEY (kind, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS pricing (
+		version                   TEXT,
+		sku                       TEXT,
+		offer_term_code           TEXT,
+		region                    TEXT,
+		instance_type             TEXT,
+		usage_type                TEXT,
+		lease_contract_length     TEXT,
+		purchase_option           TEXT,
+		on_demand                 REAL,
+		reserved_quantity         REAL,
+		reserved_hrs              REAL,
+		tenancy                   TEXT,
+		pre_installed             TEXT,
+		operation                 TEXT,
+		operating_system          TEXT,
+		cache_engine              TEXT,
+		database_engine           TEXT,
+		offering_class            TEXT,
+		normalization_size_factor TEXT,
+		vcpu                      TEXT,
+		memory                    TEXT,
+		instance_family           TEXT,
+		physical_processor        TEXT,
+		network_performance       TEXT,
+		current_generation        TEXT,
+		deployment_option         TEXT,
+		license_model             TEXT,
+		capacity_status           TEXT,
+		location                  TEXT,
+		database_edition          TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS pricing_region ON pricing (region)`,
+	`CREATE INDEX IF NOT EXISTS pricing_usage_type ON pricing (usage_type)`,
+	`CREATE INDEX IF NOT EXISTS pricing_instance_type ON pricing (instance_type, lease_contract_length, offering_class, purchase_option)`,
+	`CREATE TABLE IF NOT EXISTS pricing_history (
+		snapshot                  TEXT,
+		version                   TEXT,
+		sku                       TEXT,
+		offer_term_code           TEXT,
+		region                    TEXT,
+		instance_type             TEXT,
+		usage_type                TEXT,
+		lease_contract_length     TEXT,
+		purchase_option           TEXT,
+		on_demand                 REAL,
+		reserved_quantity         REAL,
+		reserved_hrs              REAL,
+		tenancy                   TEXT,
+		pre_installed             TEXT,
+		operation                 TEXT,
+		operating_system          TEXT,
+		cache_engine              TEXT,
+		database_engine           TEXT,
+		offering_class            TEXT,
+		normalization_size_factor TEXT,
+		vcpu                      TEXT,
+		memory                    TEXT,
+		instance_family           TEXT,
+		physical_processor        TEXT,
+		network_performance       TEXT,
+		current_generation        TEXT,
+		deployment_option         TEXT,
+		license_model             TEXT,
+		capacity_status           TEXT,
+		location                  TEXT,
+		database_edition          TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS pricing_history_region ON pricing_history (region, snapshot)`,
+	`CREATE INDEX IF NOT EXISTS pricing_history_version ON pricing_history (region, version)`,
+	`CREATE TABLE IF NOT EXISTS pricing_snapshot (
+		region   TEXT,
+		snapshot TEXT,
+		version  TEXT,
+		PRIMARY KEY (region, snapshot, version)
+	)`,
+	// the snapshots of a database created by an older version
+	`INSERT OR IGNORE INTO pricing_snapshot (region, snapshot, version)
+		SELECT DISTINCT region, snapshot, version FROM pricing_history
+		WHERE NOT EXISTS (SELECT 1 FROM pricing_snapshot)`,
+	`CREATE TABLE IF NOT EXISTS usage (
+		account_id        TEXT,
+		description       TEXT,
+		region            TEXT,
+		usage_type        TEXT,
+		platform          TEXT,
+		cache_engine      TEXT,
+		database_engine   TEXT,
+		tenancy           TEXT,
+		availability_zone TEXT,
+		date              TEXT,
+		instance_hour     REAL,
+		instance_num      REAL
+	)`,
+	`CREATE INDEX IF NOT EXISTS usage_date ON usage (date)`,
+	`CREATE INDEX IF NOT EXISTS usage_usage_type ON usage (usage_type)`,
+	`CREATE INDEX IF NOT EXISTS usage_account_id ON usage (account_id)`,
+	`CREATE TABLE IF NOT EXISTS reservation (
+		date                  TEXT,
+		region                TEXT,
+		usage_type            TEXT,
+		platform              TEXT,
+		cache_engine          TEXT,
+		database_engine       TEXT,
+		instance_num          REAL,
+		sku                   TEXT,
+		offer_term_code       TEXT,
+		lease_contract_length TEXT,
+		purchase_option       TEXT,
+		offering_class        TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS reservation_date ON reservation (date)`,
+	`CREATE INDEX IF NOT EXISTS reservation_usage_type ON reservation (region, usage_type)`,
+}
+
+// column is added to the tables of a database created by an older version.
+var column = []struct {
+	Table string
+	Name  string
+	Type  string
+}{
+	{"usage", "tenancy", "TEXT DEFAULT ''"},
+	{"usage", "availability_zone", "TEXT DEFAULT ''"},
+	{"pricing", "vcpu", "TEXT DEFAULT ''"},
+	{"pricing", "memory", "TEXT DEFAULT ''"},
+	{"pricing", "instance_family", "TEXT DEFAULT ''"},
+	{"pricing", "physical_processor", "TEXT DEFAULT ''"},
+	{"pricing", "network_performance", "TEXT DEFAULT ''"},
+	{"pricing", "current_generation", "TEXT DEFAULT ''"},
+	{"pricing", "deployment_option", "TEXT DEFAULT ''"},
+	{"pricing", "license_model", "TEXT DEFAULT ''"},
+	{"pricing", "capacity_status", "TEXT DEFAULT ''"},
+	{"pricing", "location", "TEXT DEFAULT ''"},
+	{"pricing_history", "vcpu", "TEXT DEFAULT ''"},
+	{"pricing_history", "memory", "TEXT DEFAULT ''"},
+	{"pricing_history", "instance_family", "TEXT DEFAULT ''"},
+	{"pricing_history", "physical_processor", "TEXT DEFAULT ''"},
+	{"pricing_history", "network_performance", "TEXT DEFAULT ''"},
+	{"pricing_history", "current_generation", "TEXT DEFAULT ''"},
+	{"pricing_history", "deployment_option", "TEXT DEFAULT ''"},
+	{"pricing_history", "license_model", "TEXT DEFAULT ''"},
+	{"pricing_history", "capacity_status", "TEXT DEFAULT ''"},
+	{"pricing_history", "location", "TEXT DEFAULT ''"},
+	{"pricing", "database_edition", "TEXT DEFAULT ''"},
+	{"pricing_history", "database_edition", "TEXT DEFAULT ''"},
+}
+
+var pricingColumn = []string{
+	"version",
+	"sku",
+	"offer_term_code",
+	"region",
+	"instance_type",
+	"usage_type",
+	"lease_contract_length",
+	"purchase_option",
+	"on_demand",
+	"reserved_quantity",
+	"reserved_hrs",
+	"tenancy",
+	"pre_installed",
+	"operation",
+	"operating_system",
+	"cache_engine",
+	"database_engine",
+	"offering_class",
+	"normalization_size_factor",
+	"vcpu",
+	"memory",
+	"instance_family",
+	"physical_processor",
+	"network_performance",
+	"current_generation",
+	"deployment_option",
+	"license_model",
+	"capacity_status",
+	"location",
+	"database_edition",
+}
+
+var usageColumn = []string{
+	"account_id",
+	"description",
+	"region",
+	"usage_type",
+	"platform",
+	"cache_engine",
+	"database_engine",
+	"tenancy",
+	"availability_zone",
+	"date",
+	"instance_hour",
+	"instance_num",
+}
+
+var reservationColumn = []string{
+	"date",
+	"region",
+	"usage_type",
+	"platform",
+	"cache_engine",
+	"database_engine",
+	"instance_num",
+	"sku",
+	"offer_term_code",
+	"lease_contract_length",
+	"purchase_option",
+	"offering_class",
+}
+
+// Reservation is a recommended reservation purchase of Quantity.InstanceNum of Price.
+type Reservation struct {
+	Date     string         `json:"date"` // YYYY-MM-DD the reservation is recommended on
+	Quantity usage.Quantity `json:"quantity"`
+	Price    pricing.Price  `json:"price"`
+}
+
+// SQLite stores pricing, usage and reservations in indexed tables of an embedded sqlite database.
+type SQLite struct {
+	DB *sql.DB
+}
+
+func SQLitePath(dir string) string {
+	return fmt.Sprintf("%s/hermes.db", dir)
+}
+
+func NewSQLite(file string) (*SQLite, error) {
+	if _, err := os.Stat(filepath.Dir(file)); os.IsNotExist(err) {
+		os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	}
+
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %v", file, err)
+	}
+
+	for _, s := range schema {
+		if _, err := db.Exec(s); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("exec %s: %v", s, err)
+		}
+	}
+
+	if err := addColumn(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("add column: %v", err)
+	}
+
+	return &SQLite{DB: db}, nil
+}
+
+// NewSQLiteReadOnly opens file read-only. Statements modifying the database fail.
+func NewSQLiteReadOnly(file string) (*SQLite, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?mode=ro", file))
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %v", file, err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %v", file, err)
+	}
+
+	return &SQLite{DB: db}, nil
+}
+
+func addColumn(db *sql.DB) error {
+	for _, c := range column {
+		var n int
+		if err := db.QueryRow(
+			`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`,
+			c.Table,
+			c.Name,
+		).Scan(&n); err != nil {
+			return fmt.Errorf("table info %s: %v", c.Table, err)
+		}
+
+		if n > 0 {
+			continue
+		}
+
+		if _, err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, c.Table, c.Name, c.Type)); err != nil {
+			return fmt.Errorf("alter table %s: %v", c.Table, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLite) ExistsPricing(region string) (bool, error) {
+	return s.exists("pricing", region)
+}
+
+func (s *SQLite) ListPricing() ([]string, error) {
+	out, err := selectString(s.DB, `SELECT name FROM entry WHERE kind = 'pricing' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("select entry: %v", err)
+	}
+
+	return out, nil
+}
+
+// WritePricing replaces the prices of region and adds the versions of a snapshot of them to pricing_snapshot
+// unless the latest snapshot has the same price list versions.
+// The prices of a version are added to pricing_history once, by the first snapshot of the version.
+func (s *SQLite) WritePricing(region string, price []pricing.Price) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM pricing WHERE region = ?`, region); err != nil {
+			return fmt.Errorf("delete pricing: %v", err)
+		}
+
+		if err := insertPricing(tx, "pricing", price); err != nil {
+			return err
+		}
+
+		var latest sql.NullString
+		if err := tx.QueryRow(`SELECT MAX(snapshot) FROM pricing_snapshot WHERE region = ?`, region).Scan(&latest); err != nil {
+			return fmt.Errorf("select pricing snapshot: %v", err)
+		}
+
+		version := pricing.Version(price)
+		if latest.Valid {
+			v, err := selectString(tx, `SELECT version FROM pricing_snapshot WHERE region = ? AND snapshot = ? ORDER BY version`, region, latest.String)
+			if err != nil {
+				return fmt.Errorf("select pricing snapshot: %v", err)
+			}
+
+			if strings.Join(v, ",") == strings.Join(version, ",") {
+				return s.entry(tx, "pricing", region)
+			}
+		}
+
+		stored, err := selectString(tx, `SELECT DISTINCT version FROM pricing_history WHERE region = ?`, region)
+		if err != nil {
+			return fmt.Errorf("select pricing history: %v", err)
+		}
+
+		exists := make(map[string]bool)
+		for _, v := range stored {
+			exists[v] = true
+		}
+
+		added := make([]pricing.Price, 0)
+		for _, p := range price {
+			if exists[p.Version] {
+				continue
+			}
+
+			added = append(added, p)
+		}
+
+		snapshot := pricing.NewSnapshot(time.Now())
+		if err := insertPricing(tx, "pricing_history", added, snapshot); err != nil {
+			return err
+		}
+
+		for _, v := range version {
+			if _, err := tx.Exec(`INSERT OR IGNORE INTO pricing_snapshot (region, snapshot, version) VALUES (?, ?, ?)`, region, snapshot, v); err != nil {
+				return fmt.Errorf("insert pricing snapshot: %v", err)
+			}
+		}
+
+		return s.entry(tx, "pricing", region)
+	})
+}
+
+// insertPricing inserts price into table. snapshot is the first column of pricing_history.
+func insertPricing(tx *sql.Tx, table string, price []pricing.Price, snapshot ...string) error {
+	column := pricingColumn
+	if len(snapshot) > 0 {
+		column = append([]string{"snapshot"}, pricingColumn...)
+	}
+
+	stmt, err := tx.Prepare(fmt.Sprintf(
+		`INSERT INTO %s (%s) VALUES (%s)`,
+		table,
+		strings.Join(column, ", "),
+		placeholder(len(column)),
+	))
+	if err != nil {
+		return fmt.Errorf("prepare: %v", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range price {
+		v := []interface{}{
+			p.Version,
+			p.SKU,
+			p.OfferTermCode,
+			p.Region,
+			p.InstanceType,
+			p.UsageType,
+			p.LeaseContractLength,
+			p.PurchaseOption,
+			p.OnDemand,
+			p.ReservedQuantity,
+			p.ReservedHrs,
+			p.Tenancy,
+			p.PreInstalled,
+			p.Operation,
+			p.OperatingSystem,
+			p.CacheEngine,
+			p.DatabaseEngine,
+			p.OfferingClass,
+			p.NormalizationSizeFactor,
+			p.VCPU,
+			p.Memory,
+			p.InstanceFamily,
+			p.PhysicalProcessor,
+			p.NetworkPerformance,
+			p.CurrentGeneration,
+			p.DeploymentOption,
+			p.LicenseModel,
+			p.CapacityStatus,
+			p.Location,
+			p.DatabaseEdition,
+		}
+
+		if len(snapshot) > 0 {
+			v = append([]interface{}{snapshot[0]}, v...)
+		}
+
+		if _, err := stmt.Exec(v...); err != nil {
+			return fmt.Errorf("insert %s %s.%s: %v", table, p.SKU, p.OfferTermCode, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLite) ReadPricing(region []string) ([]pricing.Price, error) {
+	for _, r := range region {
+		ok, err := s.ExistsPricing(r)
+		if err != nil {
+			return []pricing.Price{}, err
+		}
+
+		if !ok {
+			return []pricing.Price{}, fmt.Errorf("pricing not found: %v", r)
+		}
+	}
+
+	rows, err := s.DB.Query(
+		fmt.Sprintf(
+			`SELECT %s FROM pricing WHERE region IN (%s) ORDER BY purchase_option, lease_contract_length, instance_type, region, version`,
+			strings.Join(pricingColumn, ", "),
+			placeholder(len(region)),
+		),
+		args(region)...,
+	)
+	if err != nil {
+		return []pricing.Price{}, fmt.Errorf("select pricing: %v", err)
+	}
+	defer rows.Close()
+
+	return scanPricing(rows)
+}
+
+func scanPricing(rows *sql.Rows) ([]pricing.Price, error) {
+	price := make([]pricing.Price, 0)
+	for rows.Next() {
+		var p pricing.Price
+		if err := rows.Scan(
+			&p.Version,
+			&p.SKU,
+			&p.OfferTermCode,
+			&p.Region,
+			&p.InstanceType,
+			&p.UsageType,
+			&p.LeaseContractLength,
+			&p.PurchaseOption,
+			&p.OnDemand,
+			&p.ReservedQuantity,
+			&p.ReservedHrs,
+			&p.Tenancy,
+			&p.PreInstalled,
+			&p.Operation,
+			&p.OperatingSystem,
+			&p.CacheEngine,
+			&p.DatabaseEngine,
+			&p.OfferingClass,
+			&p.NormalizationSizeFactor,
+			&p.VCPU,
+			&p.Memory,
+			&p.InstanceFamily,
+			&p.PhysicalProcessor,
+			&p.NetworkPerformance,
+			&p.CurrentGeneration,
+			&p.DeploymentOption,
+			&p.LicenseModel,
+			&p.CapacityStatus,
+			&p.Location,
+			&p.DatabaseEdition,
+		); err != nil {
+			return []pricing.Price{}, fmt.Errorf("scan pricing: %v", err)
+		}
+
+		price = append(price, p)
+	}
+
+	return price, rows.Err()
+}
+
+func (s *SQLite) ListPricingSnapshot(region string) ([]string, error) {
+	out, err := selectString(s.DB, `SELECT DISTINCT snapshot FROM pricing_snapshot WHERE region = ? ORDER BY snapshot`, region)
+	if err != nil {
+		return nil, fmt.Errorf("select pricing snapshot: %v", err)
+	}
+
+	return out, nil
+}
+
+func (s *SQLite) ReadPricingSnapshotVersion(region, snapshot string) ([]string, error) {
+	out, err := selectString(s.DB, `SELECT version FROM pricing_snapshot WHERE region = ? AND snapshot = ? ORDER BY version`, region, snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("select pricing snapshot: %v", err)
+	}
+
+	if len(out) < 1 {
+		return nil, fmt.Errorf("snapshot not found: %s/%s", region, snapshot)
+	}
+
+	return out, nil
+}
+
+// ReadPricingSnapshot returns the prices of the versions of snapshot.
+// The prices of a version are the rows of its first snapshot in pricing_history.
+func (s *SQLite) ReadPricingSnapshot(region, snapshot string) ([]pricing.Price, error) {
+	rows, err := s.DB.Query(
+		fmt.Sprintf(
+			`SELECT %s FROM pricing_history h
+			WHERE region = ?
+			AND version IN (SELECT version FROM pricing_snapshot WHERE region = ? AND snapshot = ?)
+			AND snapshot = (SELECT MIN(snapshot) FROM pricing_history WHERE region = h.region AND version = h.version)
+			ORDER BY purchase_option, lease_contract_length, instance_type, version`,
+			strings.Join(pricingColumn, ", "),
+		),
+		region,
+		region,
+		snapshot,
+	)
+	if err != nil {
+		return []pricing.Price{}, fmt.Errorf("select pricing history: %v", err)
+	}
+	defer rows.Close()
+
+	price, err := scanPricing(rows)
+	if err != nil {
+		return []pricing.Price{}, err
+	}
+
+	if len(price) < 1 {
+		return []pricing.Price{}, fmt.Errorf("snapshot not found: %s/%s", region, snapshot)
+	}
+
+	return price, nil
+}
+
+func (s *SQLite) ExistsUsage(date usage.Date) (bool, error) {
+	return s.exists("usage", date.YYYYMM())
+}
+
+func (s *SQLite) WriteUsage(date usage.Date, quantity []usage.Quantity) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM usage WHERE date = ?`, date.YYYYMM()); err != nil {
+			return fmt.Errorf("delete usage: %v", err)
+		}
+
+		stmt, err := tx.Prepare(fmt.Sprintf(
+			`INSERT INTO usage (%s) VALUES (%s)`,
+			strings.Join(usageColumn, ", "),
+			placeholder(len(usageColumn)),
+		))
+		if err != nil {
+			return fmt.Errorf("prepare: %v", err)
+		}
+		defer stmt.Close()
+
+		for _, q := range quantity {
+			if _, err := stmt.Exec(
+				q.AccountID,
+				q.Description,
+				q.Region,
+				q.UsageType,
+				q.Platform,
+				q.CacheEngine,
+				q.DatabaseEngine,
+				q.Tenancy,
+				q.AvailabilityZone,
+				q.Date,
+				q.InstanceHour,
+				q.InstanceNum,
+			); err != nil {
+				return fmt.Errorf("insert usage %s: %v", q.UsageType, err)
+			}
+		}
+
+		return s.entry(tx, "usage", date.YYYYMM())
+	})
+}
+
+func (s *SQLite) ReadUsage(date []usage.Date) ([]usage.Quantity, error) {
+	month := make([]string, 0)
+	for _, d := range date {
+		ok, err := s.ExistsUsage(d)
+		if err != nil {
+			return []usage.Quantity{}, err
+		}
+
+		if !ok {
+			return []usage.Quantity{}, fmt.Errorf("usage not found: %v", d.YYYYMM())
+		}
+
+		month = append(month, d.YYYYMM())
+	}
+
+	rows, err := s.DB.Query(
+		fmt.Sprintf(
+			`SELECT %s FROM usage WHERE date IN (%s) ORDER BY date, usage_type, region, account_id`,
+			strings.Join(usageColumn, ", "),
+			placeholder(len(month)),
+		),
+		args(month)...,
+	)
+	if err != nil {
+		return []usage.Quantity{}, fmt.Errorf("select usage: %v", err)
+	}
+	defer rows.Close()
+
+	quantity := make([]usage.Quantity, 0)
+	for rows.Next() {
+		var q usage.Quantity
+		if err := rows.Scan(
+			&q.AccountID,
+			&q.Description,
+			&q.Region,
+			&q.UsageType,
+			&q.Platform,
+			&q.CacheEngine,
+			&q.DatabaseEngine,
+			&q.Tenancy,
+			&q.AvailabilityZone,
+			&q.Date,
+			&q.InstanceHour,
+			&q.InstanceNum,
+		); err != nil {
+			return []usage.Quantity{}, fmt.Errorf("scan usage: %v", err)
+		}
+
+		quantity = append(quantity, q)
+	}
+
+	return quantity, rows.Err()
+}
+
+// WriteReservation replaces the reservations of date with reservation.
+func (s *SQLite) WriteReservation(date string, reservation []Reservation) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM reservation WHERE date = ?`, date); err != nil {
+			return fmt.Errorf("delete reservation: %v", err)
+		}
+
+		stmt, err := tx.Prepare(fmt.Sprintf(
+			`INSERT INTO reservation (%s) VALUES (%s)`,
+			strings.Join(reservationColumn, ", "),
+			placeholder(len(reservationColumn)),
+		))
+		if err != nil {
+			return fmt.Errorf("prepare: %v", err)
+		}
+		defer stmt.Close()
+
+		for _, r := range reservation {
+			if _, err := stmt.Exec(
+				date,
+				r.Quantity.Region,
+				r.Quantity.UsageType,
+				r.Quantity.Platform,
+				r.Quantity.CacheEngine,
+				r.Quantity.DatabaseEngine,
+				r.Quantity.InstanceNum,
+				r.Price.SKU,
+				r.Price.OfferTermCode,
+				r.Price.LeaseContractLength,
+				r.Price.PurchaseOption,
+				r.Price.OfferingClass,
+			); err != nil {
+				return fmt.Errorf("insert reservation %s: %v", r.Quantity.UsageType, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// ReadReservation returns the reservations of date.
+func (s *SQLite) ReadReservation(date string) ([]Reservation, error) {
+	rows, err := s.DB.Query(
+		fmt.Sprintf(
+			`SELECT %s FROM reservation WHERE date = ? ORDER BY region, usage_type, offer_term_code`,
+			strings.Join(reservationColumn, ", "),
+		),
+		date,
+	)
+	if err != nil {
+		return []Reservation{}, fmt.Errorf("select reservation: %v", err)
+	}
+	defer rows.Close()
+
+	out := make([]Reservation, 0)
+	for rows.Next() {
+		var r Reservation
+		if err := rows.Scan(
+			&r.Date,
+			&r.Quantity.Region,
+			&r.Quantity.UsageType,
+			&r.Quantity.Platform,
+			&r.Quantity.CacheEngine,
+			&r.Quantity.DatabaseEngine,
+			&r.Quantity.InstanceNum,
+			&r.Price.SKU,
+			&r.Price.OfferTermCode,
+			&r.Price.LeaseContractLength,
+			&r.Price.PurchaseOption,
+			&r.Price.OfferingClass,
+		); err != nil {
+			return []Reservation{}, fmt.Errorf("scan reservation: %v", err)
+		}
+
+		r.Price.Region, r.Price.UsageType = r.Quantity.Region, r.Quantity.UsageType
+		out = append(out, r)
+	}
+
+	return out, rows.Err()
+}
+
+// Query runs an arbitrary sql statement and returns the column names and rows.
+func (s *SQLite) Query(query string) ([]string, [][]interface{}, error) {
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query: %v", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("columns: %v", err)
+	}
+
+	out := make([][]interface{}, 0)
+	for rows.Next() {
+		val := make([]interface{}, len(columns))
+		ptr := make([]interface{}, len(columns))
+		for i := range val {
+			ptr[i] = &val[i]
+		}
+
+		if err := rows.Scan(ptr...); err != nil {
+			return nil, nil, fmt.Errorf("scan: %v", err)
+		}
+
+		for i := range val {
+			if b, ok := val[i].([]byte); ok {
+				val[i] = string(b)
+			}
+		}
+
+		out = append(out, val)
+	}
+
+	return columns, out, rows.Err()
+}
+
+func (s *SQLite) Close() error {
+	return s.DB.Close()
+}
+
+func (s *SQLite) exists(kind, name string) (bool, error) {
+	var count int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM entry WHERE kind = ? AND name = ?`, kind, name).Scan(&count); err != nil {
+		return false, fmt.Errorf("select entry: %v", err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *SQLite) entry(tx *sql.Tx, kind, name string) error {
+	if _, err := tx.Exec(
+		`INSERT OR REPLACE INTO entry (kind, name, created_at) VALUES (?, ?, ?)`,
+		kind,
+		name,
+		time.Now().Format(time.RFC3339),
+	); err != nil {
+		return fmt.Errorf("insert entry: %v", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) tx(f func(tx *sql.Tx) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %v", err)
+	}
+
+	if err := f(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %v", err)
+	}
+
+	return nil
+}
+
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// selectString returns the first column of the rows of query.
+func selectString(q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]string, 0)
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+
+		out = append(out, v)
+	}
+
+	return out, rows.Err()
+}
+
+func placeholder(n int) string {
+	p := make([]string, n)
+	for i := range p {
+		p[i] = "?"
+	}
+
+	return strings.Join(p, ", ")
+}
+
+func args(s []string) []interface{} {
+	out := make([]interface{}, 0)
+	for i := range s {
+		out = append(out, s[i])
+	}
+
+	return out
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSQLite(SQLitePath(dir))
+	if err != nil {
+		t.Fatalf("new sqlite: %v", err)
+	}
+
+	testStorage(t, s)
+}
+
+func TestQuery(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSQLite(SQLitePath(dir))
+	if err != nil {
+		t.Fatalf("new sqlite: %v", err)
+	}
+	defer s.Close()
+
+	columns, rows, err := s.Query("SELECT kind, name FROM entry")
+	if err != nil {
+		t.Errorf("query: %v", err)
+	}
+
+	if len(columns) != 2 || len(rows) != 0 {
+		t.Errorf("columns: %v, rows: %v", columns, rows)
+	}
+}
+
+func TestQueryReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSQLite(SQLitePath(dir))
+	if err != nil {
+		t.Fatalf("new sqlite: %v", err)
+	}
+	s.Close()
+
+	ro, err := NewSQLiteReadOnly(SQLitePath(dir))
+	if err != nil {
+		t.Fatalf("new sqlite read only: %v", err)
+	}
+	defer ro.Close()
+
+	if _, _, err := ro.Query("SELECT kind, name FROM entry"); err != nil {
+		t.Errorf("query: %v", err)
+	}
+
+	if _, _, err := ro.Query("DROP TABLE entry"); err == nil {
+		t.Errorf("drop table in read only database")
+	}
+}
+
+func TestReservation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewSQLite(SQLitePath(dir))
+	if err != nil {
+		t.Fatalf("new sqlite: %v", err)
+	}
+	defer s.Close()
+
+	r := Reservation{
+		Date:     "2019-08-01",
+		Quantity: usage.Quantity{Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", InstanceNum: 10},
+		Price:    pricing.Price{SKU: "SKU1", OfferTermCode: "4NA7Y494T4", LeaseContractLength: "1yr", PurchaseOption: "All Upfront", OfferingClass: "standard"},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.WriteReservation(r.Date, []Reservation{r}); err != nil {
+			t.Fatalf("write reservation: %v", err)
+		}
+	}
+
+	out, err := s.ReadReservation(r.Date)
+	if err != nil {
+		t.Fatalf("read reservation: %v", err)
+	}
+
+	if len(out) != 1 || out[0].Quantity != r.Quantity || out[0].Price.SKU != "SKU1" || out[0].Price.OfferingClass != "standard" {
+		t.Errorf("reservation: %v", out)
+	}
+}
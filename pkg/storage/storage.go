@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+	"github.com/itsubaki/hermes/pkg/pricing"
+	"github.com/itsubaki/hermes/pkg/usage"
+)
+
+type Storage interface {
+	ExistsPricing(region string) (bool, error)
+	ListPricing() ([]string, error)
+	WritePricing(region string, price []pricing.Price) error
+	ReadPricing(region []string) ([]pricing.Price, error)
+	ListPricingSnapshot(region string) ([]string, error)
+	ReadPricingSnapshot(region, snapshot string) ([]pricing.Price, error)
+	ReadPricingSnapshotVersion(region, snapshot string) ([]string, error)
+	ExistsUsage(date usage.Date) (bool, error)
+	WriteUsage(date usage.Date, quantity []usage.Quantity) error
+	ReadUsage(date []usage.Date) ([]usage.Quantity, error)
+	Close() error
+}
+
+// Region returns region, or the regions in s when region includes pricing.AllRegion.
+func Region(s Storage, region []string) ([]string, error) {
+	for _, r := range region {
+		if r == pricing.AllRegion {
+			return s.ListPricing()
+		}
+	}
+
+	return region, nil
+}
+
+// New returns the storage backend named kind ("file" or "sqlite") under dir.
+// opt is used for the header of files written by the file backend.
+func New(kind, dir string, opt cache.Option) (Storage, error) {
+	if kind == "file" {
+		return NewFileWithOption(dir, opt), nil
+	}
+
+	if kind == "sqlite" {
+		return NewSQLite(SQLitePath(dir))
+	}
+
+	return nil, fmt.Errorf("invalid storage: %s", kind)
+}
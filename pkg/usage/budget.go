@@ -0,0 +1,104 @@
+package usage
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// CostPerRequest is the charge of a paginated cost explorer api request in USD.
+// https://aws.amazon.com/aws-cost-management/pricing/
+var CostPerRequest = 0.01
+
+var ErrBudgetExceeded = errors.New("cost explorer request budget exceeded")
+
+// Budget counts cost explorer requests by api and refuses requests beyond Max. Max of zero is unlimited.
+type Budget struct {
+	mu    sync.Mutex
+	Max   int
+	count map[string]int
+}
+
+func NewBudget(max int) *Budget {
+	return &Budget{
+		Max:   max,
+		count: make(map[string]int),
+	}
+}
+
+// Take counts a request to api, or returns ErrBudgetExceeded. A nil Budget counts nothing.
+func (b *Budget) Take(api string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.Max > 0 && b.total() >= b.Max {
+		return ErrBudgetExceeded
+	}
+
+	b.count[api]++
+	return nil
+}
+
+// Exceeded returns true when the requests reached Max. A nil Budget is never exceeded.
+func (b *Budget) Exceeded() bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.Max > 0 && b.total() >= b.Max
+}
+
+func (b *Budget) Total() int {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.total()
+}
+
+// Count returns the number of requests by api, sorted by api name.
+func (b *Budget) Count() ([]string, []int) {
+	if b == nil {
+		return []string{}, []int{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	api := make([]string, 0)
+	for k := range b.count {
+		api = append(api, k)
+	}
+	sort.Strings(api)
+
+	count := make([]int, 0)
+	for _, a := range api {
+		count = append(count, b.count[a])
+	}
+
+	return api, count
+}
+
+// Cost returns the estimated charge of the requests in USD.
+func (b *Budget) Cost() float64 {
+	return float64(b.Total()) * CostPerRequest
+}
+
+func (b *Budget) total() int {
+	total := 0
+	for _, v := range b.count {
+		total = total + v
+	}
+
+	return total
+}
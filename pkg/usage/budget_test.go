@@ -0,0 +1,74 @@
+package usage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(3)
+	for _, api := range []string{"GetDimensionValues", "GetCostAndUsage", "GetCostAndUsage"} {
+		if err := b.Take(api); err != nil {
+			t.Errorf("take: %v", err)
+		}
+	}
+
+	if err := b.Take("GetCostAndUsage"); err != ErrBudgetExceeded {
+		t.Errorf("expected: %v, actual: %v", ErrBudgetExceeded, err)
+	}
+
+	if !b.Exceeded() || b.Total() != 3 || b.Cost() != 0.03 {
+		t.Errorf("exceeded: %v, total: %v, cost: %v", b.Exceeded(), b.Total(), b.Cost())
+	}
+
+	api, count := b.Count()
+	if len(api) != 2 || api[0] != "GetCostAndUsage" || count[0] != 2 || api[1] != "GetDimensionValues" || count[1] != 1 {
+		t.Errorf("api: %v, count: %v", api, count)
+	}
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	var n *Budget
+	if err := n.Take("GetCostAndUsage"); err != nil {
+		t.Errorf("take: %v", err)
+	}
+
+	if api, _ := n.Count(); n.Exceeded() || n.Total() != 0 || n.Cost() != 0 || len(api) != 0 {
+		t.Errorf("nil budget: %v, %v, %v, %v", n.Exceeded(), n.Total(), n.Cost(), api)
+	}
+
+	b := NewBudget(0)
+	for i := 0; i < 1000; i++ {
+		if err := b.Take("GetCostAndUsage"); err != nil {
+			t.Errorf("take: %v", err)
+		}
+	}
+}
+
+func TestFetchWithBudget(t *testing.T) {
+	f := &fakeCostExplorer{
+		Account:   []string{"123456789012", "210987654321", "111111111111"},
+		UsageType: []string{"APN1-BoxUsage:c4.large"},
+	}
+
+	b := NewBudget(3)
+	_, err := FetchWithInput(&FetchInput{
+		Start:  "2019-08-01",
+		End:    "2019-09-01",
+		Client: f,
+		Budget: b,
+	})
+
+	perr, ok := err.(*PartialError)
+	if !ok {
+		t.Fatalf("expected partial error: %v", err)
+	}
+
+	if len(perr.Failed) != 2 || !strings.Contains(perr.Failed[0].Error(), ErrBudgetExceeded.Error()) {
+		t.Errorf("failed: %v", perr.Failed)
+	}
+
+	if f.Requests != 3 || b.Total() != 3 {
+		t.Errorf("requests: %v, budget: %v", f.Requests, b.Total())
+	}
+}
@@ -0,0 +1,90 @@
+package usage
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+)
+
+// CostExplorer is the subset of the cost explorer api used by hermes.
+type CostExplorer interface {
+	GetCostAndUsage(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error)
+	GetDimensionValues(input *costexplorer.GetDimensionValuesInput) (*costexplorer.GetDimensionValuesOutput, error)
+}
+
+func NewCostExplorer() CostExplorer {
+	return costexplorer.New(session.Must(session.NewSession()))
+}
+
+// Client counts each request in Budget, waits for Limiter
+// and retries throttled requests with exponential backoff.
+type Client struct {
+	CostExplorer CostExplorer
+	Limiter      *Limiter
+	Budget       *Budget
+	MaxRetries   int
+	Backoff      time.Duration
+}
+
+func (c *Client) GetCostAndUsage(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	var out *costexplorer.GetCostAndUsageOutput
+	err := c.retry("GetCostAndUsage", func() error {
+		o, err := c.CostExplorer.GetCostAndUsage(input)
+		out = o
+		return err
+	})
+
+	return out, err
+}
+
+func (c *Client) GetDimensionValues(input *costexplorer.GetDimensionValuesInput) (*costexplorer.GetDimensionValuesOutput, error) {
+	var out *costexplorer.GetDimensionValuesOutput
+	err := c.retry("GetDimensionValues", func() error {
+		o, err := c.CostExplorer.GetDimensionValues(input)
+		out = o
+		return err
+	})
+
+	return out, err
+}
+
+func (c *Client) retry(api string, f func() error) error {
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for i := 0; ; i++ {
+		if err := c.Budget.Take(api); err != nil {
+			return err
+		}
+
+		c.Limiter.Wait()
+
+		err := f()
+		if err == nil || !Throttled(err) || i >= c.MaxRetries {
+			return err
+		}
+
+		d := backoff * time.Duration(1<<uint(i))
+		time.Sleep(d/2 + time.Duration(rand.Int63n(int64(d/2)+1)))
+	}
+}
+
+// Throttled returns true when err is a cost explorer rate limit error.
+func Throttled(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+
+	switch aerr.Code() {
+	case costexplorer.ErrCodeLimitExceededException, "ThrottlingException", "TooManyRequestsException":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,244 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+)
+
+type awsError struct {
+	code string
+}
+
+func (e awsError) Error() string   { return e.code }
+func (e awsError) Code() string    { return e.code }
+func (e awsError) Message() string { return e.code }
+func (e awsError) OrigErr() error  { return nil }
+
+// fakeCostExplorer returns BoxUsage of 744 hours for each account and usage type in the filter.
+// Grouped by LINKED_ACCOUNT, it returns usage of every account.
+type fakeCostExplorer struct {
+	mu        sync.Mutex
+	Account   []string
+	UsageType []string
+	Throttle  int
+	Fail      map[string]bool
+	Requests  int
+}
+
+func (f *fakeCostExplorer) GetCostAndUsage(input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Requests++
+	if f.Throttle > 0 {
+		f.Throttle--
+		return nil, awsError{code: "ThrottlingException"}
+	}
+
+	account, usageType := "", make([]string, 0)
+	if input.Filter != nil {
+		for _, e := range input.Filter.And {
+			if *e.Dimensions.Key == "LINKED_ACCOUNT" {
+				account = *e.Dimensions.Values[0]
+			}
+
+			if *e.Dimensions.Key == "USAGE_TYPE" {
+				for _, v := range e.Dimensions.Values {
+					usageType = append(usageType, *v)
+				}
+			}
+		}
+	}
+
+	if f.Fail[account] {
+		return nil, fmt.Errorf("access denied")
+	}
+
+	groups := make([]*costexplorer.Group, 0)
+	if len(input.GroupBy) > 0 && *input.GroupBy[0].Key == "LINKED_ACCOUNT" {
+		for _, a := range f.Account {
+			if f.Fail[a] {
+				continue
+			}
+
+			for _, u := range usageType {
+				groups = append(groups, &costexplorer.Group{
+					Keys:    []*string{aws.String(a), aws.String(u)},
+					Metrics: map[string]*costexplorer.MetricValue{"UsageQuantity": {Amount: aws.String("744")}},
+				})
+			}
+		}
+
+		return &costexplorer.GetCostAndUsageOutput{
+			ResultsByTime: []*costexplorer.ResultByTime{{Groups: groups}},
+		}, nil
+	}
+
+	for _, u := range usageType {
+		keys := []*string{aws.String(u), aws.String("Linux/UNIX")}
+		groups = append(groups, &costexplorer.Group{
+			Keys:    keys[:len(input.GroupBy)],
+			Metrics: map[string]*costexplorer.MetricValue{"UsageQuantity": {Amount: aws.String("744")}},
+		})
+	}
+
+	return &costexplorer.GetCostAndUsageOutput{
+		ResultsByTime: []*costexplorer.ResultByTime{{Groups: groups}},
+	}, nil
+}
+
+func (f *fakeCostExplorer) GetDimensionValues(input *costexplorer.GetDimensionValuesInput) (*costexplorer.GetDimensionValuesOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Requests++
+	value := []string{"Linux/UNIX"}
+	switch *input.Dimension {
+	case "LINKED_ACCOUNT":
+		value = f.Account
+	case "USAGE_TYPE":
+		value = f.UsageType
+	}
+
+	out := make([]*costexplorer.DimensionValuesWithAttributes, 0)
+	for i := range value {
+		out = append(out, &costexplorer.DimensionValuesWithAttributes{
+			Value:      aws.String(value[i]),
+			Attributes: map[string]*string{"description": aws.String(fmt.Sprintf("account-%d", i))},
+		})
+	}
+
+	return &costexplorer.GetDimensionValuesOutput{DimensionValues: out}, nil
+}
+
+func TestClientRetry(t *testing.T) {
+	cases := []struct {
+		Throttle   int
+		MaxRetries int
+		Error      bool
+	}{
+		{0, 0, false},
+		{2, 3, false},
+		{4, 3, true},
+	}
+
+	for _, tt := range cases {
+		f := &fakeCostExplorer{Throttle: tt.Throttle}
+		c := &Client{CostExplorer: f, MaxRetries: tt.MaxRetries, Backoff: time.Millisecond}
+
+		_, err := c.GetCostAndUsage(&costexplorer.GetCostAndUsageInput{})
+		if (err != nil) != tt.Error {
+			t.Errorf("expected: %v, actual: %v", tt.Error, err)
+		}
+
+		if err != nil && !Throttled(err) {
+			t.Errorf("not throttled: %v", err)
+		}
+	}
+}
+
+func TestThrottled(t *testing.T) {
+	cases := []struct {
+		Err       error
+		Throttled bool
+	}{
+		{awsError{code: "ThrottlingException"}, true},
+		{awsError{code: costexplorer.ErrCodeLimitExceededException}, true},
+		{awsError{code: "AccessDeniedException"}, false},
+		{fmt.Errorf("ThrottlingException"), false},
+	}
+
+	for _, tt := range cases {
+		if Throttled(tt.Err) != tt.Throttled {
+			t.Errorf("expected: %v, actual: %v", tt.Throttled, tt.Err)
+		}
+	}
+}
+
+func TestFetchWithInput(t *testing.T) {
+	f := &fakeCostExplorer{
+		Account:   []string{"123456789012", "210987654321", "111111111111"},
+		UsageType: []string{"APN1-BoxUsage:c4.large", "APN1-BoxUsage:c4.xlarge"},
+		Fail:      map[string]bool{"210987654321": true},
+	}
+
+	quantity, err := FetchWithInput(&FetchInput{
+		Start:    "2019-08-01",
+		End:      "2019-09-01",
+		Client:   f,
+		Parallel: 4,
+	})
+
+	perr, ok := err.(*PartialError)
+	if !ok {
+		t.Fatalf("expected partial error: %v", err)
+	}
+
+	for _, e := range perr.Failed {
+		if e.AccountID != "210987654321" || !strings.HasPrefix(e.Func, "fetch") {
+			t.Errorf("failed: %v", e)
+		}
+	}
+
+	if len(quantity) != 4 {
+		t.Errorf("quantity: %v", quantity)
+	}
+
+	// 2 dimension values and 1 BoxUsage request for each account. the other families have no usage.
+	if f.Requests != 5 {
+		t.Errorf("requests: %v", f.Requests)
+	}
+
+	for _, q := range quantity {
+		if q.AccountID == "210987654321" || q.Region != "ap-northeast-1" || q.Date != "2019-08" || q.InstanceNum != 1 {
+			t.Errorf("quantity: %v", q)
+		}
+	}
+}
+
+func TestFetchNode(t *testing.T) {
+	f := &fakeCostExplorer{
+		Account:   []string{"123456789012"},
+		UsageType: []string{"APN1-Node:dc2.large", "APN1-ESInstance:r5.large.search", "APN1-ReadCapacityUnit-Hrs", "APN1-NodeUsage:cache.r5.large", "APN1-NodeUsage:db.r6g.large"},
+	}
+
+	quantity, err := FetchWithInput(&FetchInput{
+		Start:  "2019-08-01",
+		End:    "2019-09-01",
+		Client: f,
+	})
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+
+	expected := map[string]string{
+		"APN1-Node:dc2.large":             "dc2.large",
+		"APN1-ESInstance:r5.large.search": "r5.large.search",
+		"APN1-ReadCapacityUnit-Hrs":       "",
+		"APN1-NodeUsage:db.r6g.large":     "db.r6g.large",
+	}
+
+	node := 0
+	for _, q := range quantity {
+		it, ok := expected[q.UsageType]
+		if !ok {
+			continue
+		}
+		node++
+
+		if q.InstanceType() != it || len(q.Platform) > 0 || len(q.CacheEngine) > 0 || q.Region != "ap-northeast-1" || q.InstanceNum != 1 {
+			t.Errorf("quantity: %v", q)
+		}
+	}
+
+	// MemoryDB usage is fetched by usage type only, not by cache engine.
+	if node != 4 {
+		t.Errorf("quantity: %v", quantity)
+	}
+}
@@ -0,0 +1,218 @@
+package usage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+)
+
+// UsageFamily is a family of usage types and the dimension its usage is broken down by.
+// The usage of a family without a dimension is broken down by usage type only.
+type UsageFamily struct {
+	UsageType string
+	Dimension string
+}
+
+var UsageFamilyList = []UsageFamily{
+	{UsageType: "BoxUsage", Dimension: "PLATFORM"},
+	{UsageType: MemoryDBNodeUsage}, // before NodeUsage of ElastiCache
+	{UsageType: "NodeUsage", Dimension: "CACHE_ENGINE"},
+	{UsageType: "InstanceUsage", Dimension: "DATABASE_ENGINE"},
+	{UsageType: "Multi-AZUsage", Dimension: "DATABASE_ENGINE"},
+	{UsageType: "Node:"},            // Redshift
+	{UsageType: "ESInstance:"},      // OpenSearch
+	{UsageType: "CapacityUnit-Hrs"}, // DynamoDB
+}
+
+// usageFamily returns the family of usageType.
+func usageFamily(usageType string) (UsageFamily, bool) {
+	for _, f := range UsageFamilyList {
+		if strings.Contains(usageType, f.UsageType) {
+			return f, true
+		}
+	}
+
+	return UsageFamily{}, false
+}
+
+// FetchConsolidated returns the same quantities as FetchWithInput
+// with a few paged requests grouped by LINKED_ACCOUNT and USAGE_TYPE for all accounts,
+// one for each value of the family's dimension (Linux/UNIX, Windows, Redis, MySQL, ...),
+// instead of a request for each account and family.
+func FetchConsolidated(in *FetchInput) ([]Quantity, error) {
+	if in.Client == nil {
+		in.Client = NewCostExplorer()
+	}
+
+	c := &Client{
+		CostExplorer: in.Client,
+		Limiter:      in.Limiter,
+		Budget:       in.Budget,
+		MaxRetries:   in.MaxRetries,
+	}
+
+	linkedAccount := in.Account
+	if len(linkedAccount) < 1 {
+		a, err := FetchLinkedAccount(c, in.Start, in.End)
+		if err != nil {
+			return nil, fmt.Errorf("get linked account: %v", err)
+		}
+
+		linkedAccount = a
+	}
+
+	description := make(map[string]string)
+	for _, a := range linkedAccount {
+		description[a.ID] = a.Description
+	}
+
+	usageType := in.UsageType
+	if len(usageType) < 1 {
+		u, err := FetchUsageType(c, in.Start, in.End)
+		if err != nil {
+			return nil, fmt.Errorf("get usage type: %v", err)
+		}
+
+		usageType = u
+	}
+
+	dimension := make(map[string][]string)
+	out := make([]Quantity, 0)
+	failed := make([]FetchError, 0)
+	for _, f := range UsageFamilyList {
+		ut := make([]string, 0)
+		for i := range usageType {
+			if family, ok := usageFamily(usageType[i]); !ok || family != f {
+				continue
+			}
+
+			ut = append(ut, usageType[i])
+		}
+
+		if len(ut) < 1 {
+			continue
+		}
+
+		if len(f.Dimension) < 1 {
+			dimension[f.Dimension] = []string{""}
+		}
+
+		if _, ok := dimension[f.Dimension]; !ok {
+			val, err := fetchDimensionValues(c, f.Dimension, in.Start, in.End)
+			if err != nil {
+				return nil, fmt.Errorf("get %s: %v", f.Dimension, err)
+			}
+
+			value := make([]string, 0)
+			for _, v := range val {
+				value = append(value, *v.Value)
+			}
+
+			dimension[f.Dimension] = value
+		}
+
+		for _, v := range dimension[f.Dimension] {
+			q, err := fetchConsolidatedQuantity(c, in.Start, in.End, ut, f.Dimension, v)
+			if err != nil {
+				name := f.UsageType
+				if len(f.Dimension) > 0 {
+					name = fmt.Sprintf("%s %s=%s", f.UsageType, f.Dimension, v)
+				}
+
+				failed = append(failed, FetchError{
+					Func: name,
+					Err:  err,
+				})
+				continue
+			}
+
+			for i := range q {
+				q[i].Description = description[q[i].AccountID]
+			}
+
+			out = append(out, q...)
+		}
+	}
+
+	Sort(out)
+	if len(failed) > 0 {
+		return out, &PartialError{Failed: failed}
+	}
+
+	return out, nil
+}
+
+func fetchConsolidatedQuantity(c CostExplorer, start, end string, usageType []string, dimension, value string) ([]Quantity, error) {
+	ut := make([]*string, 0)
+	for i := range usageType {
+		ut = append(ut, aws.String(usageType[i]))
+	}
+
+	input := costexplorer.GetCostAndUsageInput{
+		Metrics:     []*string{aws.String("UsageQuantity")},
+		Granularity: aws.String("MONTHLY"),
+		GroupBy: []*costexplorer.GroupDefinition{
+			{
+				Key:  aws.String("LINKED_ACCOUNT"),
+				Type: aws.String("DIMENSION"),
+			},
+			{
+				Key:  aws.String("USAGE_TYPE"),
+				Type: aws.String("DIMENSION"),
+			},
+		},
+		TimePeriod: &costexplorer.DateInterval{
+			Start: &start,
+			End:   &end,
+		},
+		Filter: &costexplorer.Expression{
+			And: []*costexplorer.Expression{
+				{
+					Dimensions: &costexplorer.DimensionValues{
+						Key:    aws.String("USAGE_TYPE"),
+						Values: ut,
+					},
+				},
+			},
+		},
+	}
+
+	if len(dimension) > 0 {
+		input.Filter.And = append(input.Filter.And, &costexplorer.Expression{
+			Dimensions: &costexplorer.DimensionValues{
+				Key:    aws.String(dimension),
+				Values: []*string{aws.String(value)},
+			},
+		})
+	}
+
+	out := make([]Quantity, 0)
+	for {
+		usage, err := c.GetCostAndUsage(&input)
+		if err != nil {
+			return nil, fmt.Errorf("get cost and usage. %s=%s: %v", dimension, value, err)
+		}
+
+		for _, r := range usage.ResultsByTime {
+			for _, g := range r.Groups {
+				q, ok := newQuantity(start, *g.Keys[1], *g.Metrics["UsageQuantity"].Amount)
+				if !ok {
+					continue
+				}
+
+				q.AccountID = *g.Keys[0]
+				setDimension(&q, dimension, value)
+
+				out = append(out, q)
+			}
+		}
+
+		if usage.NextPageToken == nil || len(*usage.NextPageToken) < 1 {
+			return out, nil
+		}
+
+		input.NextPageToken = usage.NextPageToken
+	}
+}
@@ -0,0 +1,67 @@
+package usage
+
+import (
+	"testing"
+)
+
+func TestFetchConsolidated(t *testing.T) {
+	f := &fakeCostExplorer{
+		Account:   []string{"123456789012", "210987654321", "111111111111"},
+		UsageType: []string{"APN1-BoxUsage:c4.large", "APN1-BoxUsage:c4.xlarge"},
+	}
+
+	quantity, err := FetchConsolidated(&FetchInput{
+		Start:  "2019-08-01",
+		End:    "2019-09-01",
+		Client: f,
+	})
+	if err != nil {
+		t.Fatalf("fetch consolidated: %v", err)
+	}
+
+	if len(quantity) != 6 {
+		t.Errorf("quantity: %v", quantity)
+	}
+
+	// 2 dimension values, 1 PLATFORM value and 1 BoxUsage request for all accounts.
+	if f.Requests != 4 {
+		t.Errorf("requests: %v", f.Requests)
+	}
+
+	for _, q := range quantity {
+		if len(q.AccountID) < 1 || len(q.Description) < 1 || q.Platform != "Linux/UNIX" || q.Region != "ap-northeast-1" || q.Date != "2019-08" || q.InstanceNum != 1 {
+			t.Errorf("quantity: %v", q)
+		}
+	}
+}
+
+func TestFetchConsolidatedNode(t *testing.T) {
+	f := &fakeCostExplorer{
+		Account:   []string{"123456789012", "210987654321"},
+		UsageType: []string{"APN1-Node:dc2.large", "APN1-ESInstance:r5.large.search", "APN1-NodeUsage:db.r6g.large"},
+	}
+
+	quantity, err := FetchConsolidated(&FetchInput{
+		Start:  "2019-08-01",
+		End:    "2019-09-01",
+		Client: f,
+	})
+	if err != nil {
+		t.Fatalf("fetch consolidated: %v", err)
+	}
+
+	if len(quantity) != 6 {
+		t.Errorf("quantity: %v", quantity)
+	}
+
+	// no dimension values. 1 Node, 1 ESInstance and 1 MemoryDB request for all accounts.
+	if f.Requests != 5 {
+		t.Errorf("requests: %v", f.Requests)
+	}
+
+	for _, q := range quantity {
+		if len(q.AccountID) < 1 || len(q.Platform) > 0 || len(q.CacheEngine) > 0 || q.Region != "ap-northeast-1" {
+			t.Errorf("quantity: %v", q)
+		}
+	}
+}
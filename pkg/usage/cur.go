@@ -0,0 +1,379 @@
+package usage
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LineItem is a line item of an AWS Cost and Usage Report.
+type LineItem struct {
+	AccountID        string
+	AccountName      string
+	LineItemType     string
+	UsageType        string
+	UsageStartDate   string
+	UsageAmount      float64
+	AvailabilityZone string
+	OperatingSystem  string
+	PreInstalledSW   string
+	LicenseModel     string
+	Tenancy          string
+	DatabaseEngine   string
+	CacheEngine      string
+}
+
+const (
+	curAccountID = iota
+	curAccountName
+	curLineItemType
+	curUsageType
+	curUsageStartDate
+	curUsageAmount
+	curAvailabilityZone
+	curOperatingSystem
+	curPreInstalledSW
+	curLicenseModel
+	curTenancy
+	curDatabaseEngine
+	curCacheEngine
+)
+
+// curColumn is the column name of each LineItem field in CSV and Parquet reports.
+var curColumn = []struct {
+	CSV      string
+	Parquet  string
+	Required bool
+}{
+	{"lineItem/UsageAccountId", "line_item_usage_account_id", true},
+	{"lineItem/UsageAccountName", "line_item_usage_account_name", false},
+	{"lineItem/LineItemType", "line_item_line_item_type", true},
+	{"lineItem/UsageType", "line_item_usage_type", true},
+	{"lineItem/UsageStartDate", "line_item_usage_start_date", true},
+	{"lineItem/UsageAmount", "line_item_usage_amount", true},
+	{"lineItem/AvailabilityZone", "line_item_availability_zone", false},
+	{"product/operatingSystem", "product_operating_system", false},
+	{"product/preInstalledSw", "product_pre_installed_sw", false},
+	{"product/licenseModel", "product_license_model", false},
+	{"product/tenancy", "product_tenancy", false},
+	{"product/databaseEngine", "product_database_engine", false},
+	{"product/cacheEngine", "product_cache_engine", false},
+}
+
+// curUsage is the line item types counted as usage.
+// RI and Savings Plans covered usage is reported as DiscountedUsage and SavingsPlanCoveredUsage.
+var curUsage = map[string]bool{
+	"Usage":                   true,
+	"DiscountedUsage":         true,
+	"SavingsPlanCoveredUsage": true,
+}
+
+func newLineItem(v []string) (LineItem, error) {
+	amount := 0.0
+	if len(v[curUsageAmount]) > 0 {
+		a, err := strconv.ParseFloat(v[curUsageAmount], 64)
+		if err != nil {
+			return LineItem{}, fmt.Errorf("parse usage amount: %v", err)
+		}
+
+		amount = a
+	}
+
+	return LineItem{
+		AccountID:        v[curAccountID],
+		AccountName:      v[curAccountName],
+		LineItemType:     v[curLineItemType],
+		UsageType:        v[curUsageType],
+		UsageStartDate:   v[curUsageStartDate],
+		UsageAmount:      amount,
+		AvailabilityZone: v[curAvailabilityZone],
+		OperatingSystem:  v[curOperatingSystem],
+		PreInstalledSW:   v[curPreInstalledSW],
+		LicenseModel:     v[curLicenseModel],
+		Tenancy:          v[curTenancy],
+		DatabaseEngine:   v[curDatabaseEngine],
+		CacheEngine:      v[curCacheEngine],
+	}, nil
+}
+
+// ReadCUR returns the box, node, instance and Multi-AZ usage of the CUR files in path.
+// path is a .csv, .csv.gz or .parquet file, or a directory searched for them recursively.
+func ReadCUR(path string) ([]Quantity, error) {
+	file := make([]string, 0)
+	if err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		if strings.HasSuffix(p, ".csv") || strings.HasSuffix(p, ".csv.gz") || strings.HasSuffix(p, ".parquet") {
+			file = append(file, p)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("walk %s: %v", path, err)
+	}
+
+	if len(file) < 1 {
+		return nil, fmt.Errorf("cur file not found: %s", path)
+	}
+
+	sum := newCURSum()
+	for _, f := range file {
+		read := ReadCURCSV
+		if strings.HasSuffix(f, ".parquet") {
+			read = ReadCURParquet
+		}
+
+		if err := read(f, sum.Add); err != nil {
+			return nil, fmt.Errorf("read %s: %v", f, err)
+		}
+	}
+
+	return sum.Quantity(), nil
+}
+
+// ReplaceAccount returns cached with the quantities of the accounts in imported replaced by imported.
+// The quantities of the other accounts in cached are kept.
+func ReplaceAccount(cached, imported []Quantity) []Quantity {
+	account := make(map[string]bool)
+	for _, q := range imported {
+		account[q.AccountID] = true
+	}
+
+	out := make([]Quantity, 0)
+	for _, q := range cached {
+		if account[q.AccountID] {
+			continue
+		}
+
+		out = append(out, q)
+	}
+
+	out = append(out, imported...)
+	Sort(out)
+
+	return out
+}
+
+// ReadCURCSV calls f for each line item of a CSV report. A .gz file is decompressed.
+func ReadCURCSV(file string, f func(LineItem)) error {
+	fp, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("open: %v", err)
+	}
+	defer fp.Close()
+
+	var r io.Reader = fp
+	if strings.HasSuffix(file, ".gz") {
+		gz, err := gzip.NewReader(fp)
+		if err != nil {
+			return fmt.Errorf("gzip: %v", err)
+		}
+		defer gz.Close()
+
+		r = gz
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return fmt.Errorf("read header: %v", err)
+	}
+
+	index := make(map[string]int)
+	for i, h := range header {
+		index[strings.TrimPrefix(h, "\ufeff")] = i
+	}
+
+	column := make([]int, len(curColumn))
+	for i, c := range curColumn {
+		column[i] = -1
+		if j, ok := index[c.CSV]; ok {
+			column[i] = j
+			continue
+		}
+
+		if j, ok := index[c.Parquet]; ok {
+			column[i] = j
+			continue
+		}
+
+		if c.Required {
+			return fmt.Errorf("column not found: %s", c.CSV)
+		}
+	}
+
+	v := make([]string, len(curColumn))
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("read line %d: %v", line, err)
+		}
+
+		for i, j := range column {
+			v[i] = ""
+			if j > -1 && j < len(record) {
+				v[i] = record[j]
+			}
+		}
+
+		item, err := newLineItem(v)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+
+		f(item)
+	}
+}
+
+// Platform returns the cost explorer PLATFORM of an EC2 line item.
+func Platform(operatingSystem, preInstalledSW, licenseModel string) string {
+	switch operatingSystem {
+	case "Linux", "Windows":
+	case "RHEL":
+		return "Red Hat Enterprise Linux"
+	case "SUSE":
+		return "SUSE Linux"
+	default:
+		return operatingSystem
+	}
+
+	if operatingSystem == "Windows" && licenseModel == "Bring your own license" {
+		return "Windows (BYOL)"
+	}
+
+	switch preInstalledSW {
+	case "SQL Std":
+		return fmt.Sprintf("%s with SQL Standard", operatingSystem)
+	case "SQL Web":
+		return fmt.Sprintf("%s with SQL Web", operatingSystem)
+	case "SQL Ent":
+		return fmt.Sprintf("%s with SQL Enterprise", operatingSystem)
+	}
+
+	if operatingSystem == "Linux" {
+		return "Linux/UNIX"
+	}
+
+	return operatingSystem
+}
+
+type curSum struct {
+	key      []string
+	quantity map[string]*curQuantity
+}
+
+type curQuantity struct {
+	Quantity
+	Month string
+	Hour  float64
+}
+
+func newCURSum() *curSum {
+	return &curSum{
+		key:      make([]string, 0),
+		quantity: make(map[string]*curQuantity),
+	}
+}
+
+// Add sums up the usage hours of a line item by month, account, usage type, platform or engine, tenancy and availability zone.
+func (s *curSum) Add(item LineItem) {
+	if !curUsage[item.LineItemType] || len(item.UsageStartDate) < 7 {
+		return
+	}
+
+	family, ok := usageFamily(item.UsageType)
+	if !ok {
+		return
+	}
+	dimension := family.Dimension
+
+	q := Quantity{
+		AccountID:        item.AccountID,
+		Description:      item.AccountName,
+		UsageType:        item.UsageType,
+		Tenancy:          item.Tenancy,
+		AvailabilityZone: item.AvailabilityZone,
+	}
+
+	switch dimension {
+	case "PLATFORM":
+		setDimension(&q, dimension, Platform(item.OperatingSystem, item.PreInstalledSW, item.LicenseModel))
+	case "CACHE_ENGINE":
+		setDimension(&q, dimension, item.CacheEngine)
+	case "DATABASE_ENGINE":
+		setDimension(&q, dimension, item.DatabaseEngine)
+	}
+
+	month := item.UsageStartDate[:7]
+	key := fmt.Sprintf(
+		"%s/%s/%s/%s%s%s/%s/%s",
+		month,
+		q.AccountID,
+		q.UsageType,
+		q.Platform,
+		q.CacheEngine,
+		q.DatabaseEngine,
+		q.Tenancy,
+		q.AvailabilityZone,
+	)
+
+	v, ok := s.quantity[key]
+	if !ok {
+		v = &curQuantity{Quantity: q, Month: month}
+		s.quantity[key] = v
+		s.key = append(s.key, key)
+	}
+
+	if len(v.Description) < 1 {
+		v.Description = q.Description
+	}
+
+	v.Hour += item.UsageAmount
+}
+
+func (s *curSum) Quantity() []Quantity {
+	out := make([]Quantity, 0)
+	for _, k := range s.key {
+		v := s.quantity[k]
+
+		q, ok := newQuantity(
+			fmt.Sprintf("%s-01", v.Month),
+			v.UsageType,
+			strconv.FormatFloat(v.Hour, 'f', -1, 64),
+		)
+		if !ok {
+			continue
+		}
+
+		q.AccountID = v.AccountID
+		q.Description = v.Description
+		q.Platform = v.Platform
+		q.CacheEngine = v.CacheEngine
+		q.DatabaseEngine = v.DatabaseEngine
+		q.Tenancy = v.Tenancy
+		q.AvailabilityZone = v.AvailabilityZone
+
+		out = append(out, q)
+	}
+
+	Sort(out)
+	return out
+}
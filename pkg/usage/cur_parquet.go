@@ -0,0 +1,113 @@
+package usage
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/common"
+	"github.com/xitongsys/parquet-go/reader"
+	"github.com/xitongsys/parquet-go/types"
+)
+
+// curParquetBatch is the number of rows read from each column at once.
+const curParquetBatch = 10000
+
+// ReadCURParquet calls f for each line item of a Parquet report.
+func ReadCURParquet(file string, f func(LineItem)) error {
+	fp, err := local.NewLocalFileReader(file)
+	if err != nil {
+		return fmt.Errorf("open: %v", err)
+	}
+	defer fp.Close()
+
+	pr, err := reader.NewParquetColumnReader(fp, 1)
+	if err != nil {
+		return fmt.Errorf("new reader: %v", err)
+	}
+	defer pr.ReadStop()
+
+	root := pr.SchemaHandler.GetRootExName()
+	path := make([]string, len(curColumn))
+	for i, c := range curColumn {
+		p := fmt.Sprintf("%s%s%s", root, common.PAR_GO_PATH_DELIMITER, c.Parquet)
+		if _, err := pr.SchemaHandler.ConvertToInPathStr(p); err != nil {
+			if c.Required {
+				return fmt.Errorf("column not found: %s", c.Parquet)
+			}
+
+			continue
+		}
+
+		path[i] = p
+	}
+
+	v := make([]string, len(curColumn))
+	for rows := pr.GetNumRows(); rows > 0; rows -= curParquetBatch {
+		num := int64(curParquetBatch)
+		if rows < num {
+			num = rows
+		}
+
+		column := make([][]interface{}, len(curColumn))
+		for i, p := range path {
+			if len(p) < 1 {
+				continue
+			}
+
+			values, _, _, err := pr.ReadColumnByPath(p, num)
+			if err != nil {
+				return fmt.Errorf("read %s: %v", curColumn[i].Parquet, err)
+			}
+
+			column[i] = values
+		}
+
+		for j := int64(0); j < num; j++ {
+			for i := range column {
+				v[i] = ""
+				if j < int64(len(column[i])) {
+					v[i] = parquetString(column[i][j], i == curUsageStartDate)
+				}
+			}
+
+			item, err := newLineItem(v)
+			if err != nil {
+				return fmt.Errorf("row %d: %v", j, err)
+			}
+
+			f(item)
+		}
+	}
+
+	return nil
+}
+
+// parquetString returns v as a string. Timestamps are formatted as in CSV reports.
+func parquetString(v interface{}, timestamp bool) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if timestamp && len(t) == 12 {
+			// INT96
+			return types.INT96ToTime(t).UTC().Format(time.RFC3339)
+		}
+
+		return t
+	case int64:
+		if timestamp {
+			// TIMESTAMP_MILLIS
+			return time.Unix(0, t*int64(time.Millisecond)).UTC().Format(time.RFC3339)
+		}
+
+		return strconv.FormatInt(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
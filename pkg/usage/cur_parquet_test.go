@@ -0,0 +1,93 @@
+package usage
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xitongsys/parquet-go-source/local"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+type curParquetRow struct {
+	UsageAccountID   string  `parquet:"name=line_item_usage_account_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	LineItemType     string  `parquet:"name=line_item_line_item_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	UsageType        string  `parquet:"name=line_item_usage_type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	UsageStartDate   int64   `parquet:"name=line_item_usage_start_date, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	UsageAmount      float64 `parquet:"name=line_item_usage_amount, type=DOUBLE"`
+	AvailabilityZone string  `parquet:"name=line_item_availability_zone, type=BYTE_ARRAY, convertedtype=UTF8"`
+	OperatingSystem  string  `parquet:"name=product_operating_system, type=BYTE_ARRAY, convertedtype=UTF8"`
+	PreInstalledSW   string  `parquet:"name=product_pre_installed_sw, type=BYTE_ARRAY, convertedtype=UTF8"`
+	LicenseModel     string  `parquet:"name=product_license_model, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Tenancy          string  `parquet:"name=product_tenancy, type=BYTE_ARRAY, convertedtype=UTF8"`
+	DatabaseEngine   string  `parquet:"name=product_database_engine, type=BYTE_ARRAY, convertedtype=UTF8"`
+	CacheEngine      string  `parquet:"name=product_cache_engine, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+func TestReadCURParquet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	millis := func(s string) int64 {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+
+		return v.UnixNano() / int64(time.Millisecond)
+	}
+
+	row := []curParquetRow{
+		{"123456789012", "Usage", "APN1-BoxUsage:c4.large", millis("2019-08-01T00:00:00Z"), 372, "ap-northeast-1a", "Linux", "NA", "No License required", "Shared", "", ""},
+		{"123456789012", "DiscountedUsage", "APN1-BoxUsage:c4.large", millis("2019-08-16T12:00:00Z"), 372, "ap-northeast-1a", "Linux", "NA", "No License required", "Shared", "", ""},
+		{"123456789012", "RIFee", "APN1-HeavyUsage:c4.large", millis("2019-08-01T00:00:00Z"), 744, "", "", "", "", "", "", ""},
+		{"210987654321", "Usage", "APN1-NodeUsage:cache.r5.large", millis("2019-08-01T00:00:00Z"), 1488, "ap-northeast-1a", "", "", "", "", "", "Redis"},
+	}
+
+	file := fmt.Sprintf("%s/cur-00001.snappy.parquet", dir)
+	fw, err := local.NewLocalFileWriter(file)
+	if err != nil {
+		t.Fatalf("new file writer: %v", err)
+	}
+
+	pw, err := writer.NewParquetWriter(fw, new(curParquetRow), 1)
+	if err != nil {
+		t.Fatalf("new parquet writer: %v", err)
+	}
+
+	for _, r := range row {
+		if err := pw.Write(r); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		t.Fatalf("write stop: %v", err)
+	}
+	fw.Close()
+
+	quantity, err := ReadCUR(dir)
+	if err != nil {
+		t.Fatalf("read cur: %v", err)
+	}
+
+	expected := []Quantity{
+		{AccountID: "123456789012", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", Tenancy: "Shared", AvailabilityZone: "ap-northeast-1a", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "210987654321", Region: "ap-northeast-1", UsageType: "APN1-NodeUsage:cache.r5.large", CacheEngine: "Redis", AvailabilityZone: "ap-northeast-1a", Date: "2019-08", InstanceHour: 1488, InstanceNum: 2},
+	}
+
+	if len(quantity) != len(expected) {
+		t.Fatalf("quantity: %v", quantity)
+	}
+
+	for i := range expected {
+		if quantity[i] != expected[i] {
+			t.Errorf("expected: %v, actual: %v", expected[i], quantity[i])
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package usage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+var cur = strings.Join([]string{
+	"identity/LineItemId,lineItem/UsageAccountId,lineItem/LineItemType,lineItem/UsageStartDate,lineItem/UsageType,lineItem/UsageAmount,lineItem/AvailabilityZone,product/operatingSystem,product/preInstalledSw,product/licenseModel,product/tenancy,product/databaseEngine,product/cacheEngine",
+	"1,123456789012,Usage,2019-08-01T00:00:00Z,APN1-BoxUsage:c4.large,372,ap-northeast-1a,Linux,NA,No License required,Shared,,",
+	"2,123456789012,DiscountedUsage,2019-08-16T12:00:00Z,APN1-BoxUsage:c4.large,372,ap-northeast-1a,Linux,NA,No License required,Shared,,",
+	"3,123456789012,RIFee,2019-08-01T00:00:00Z,APN1-HeavyUsage:c4.large,744,,,,,,,",
+	"4,123456789012,Usage,2019-08-01T00:00:00Z,APN1-BoxUsage:m4.large,744,ap-northeast-1c,Windows,SQL Std,License included,Dedicated,,",
+	"5,210987654321,Usage,2019-08-01T00:00:00Z,APN1-NodeUsage:cache.r5.large,1488,ap-northeast-1a,,,,,,Redis",
+	"6,210987654321,Usage,2019-08-01T00:00:00Z,APN1-DataTransfer-Out-Bytes,10,,,,,,,",
+	"7,210987654321,Usage,2019-09-01T00:00:00Z,APN1-InstanceUsage:db.r5.large,720,ap-northeast-1a,,,,,MySQL,",
+}, "\n")
+
+func TestReadCUR(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(cur))
+	gz.Close()
+
+	if err := ioutil.WriteFile(fmt.Sprintf("%s/cur-00001.csv.gz", dir), buf.Bytes(), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	quantity, err := ReadCUR(dir)
+	if err != nil {
+		t.Fatalf("read cur: %v", err)
+	}
+
+	expected := []Quantity{
+		{AccountID: "123456789012", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:c4.large", Platform: "Linux/UNIX", Tenancy: "Shared", AvailabilityZone: "ap-northeast-1a", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "123456789012", Region: "ap-northeast-1", UsageType: "APN1-BoxUsage:m4.large", Platform: "Windows with SQL Standard", Tenancy: "Dedicated", AvailabilityZone: "ap-northeast-1c", Date: "2019-08", InstanceHour: 744, InstanceNum: 1},
+		{AccountID: "210987654321", Region: "ap-northeast-1", UsageType: "APN1-InstanceUsage:db.r5.large", DatabaseEngine: "MySQL", AvailabilityZone: "ap-northeast-1a", Date: "2019-09", InstanceHour: 720, InstanceNum: 1},
+		{AccountID: "210987654321", Region: "ap-northeast-1", UsageType: "APN1-NodeUsage:cache.r5.large", CacheEngine: "Redis", AvailabilityZone: "ap-northeast-1a", Date: "2019-08", InstanceHour: 1488, InstanceNum: 2},
+	}
+
+	if len(quantity) != len(expected) {
+		t.Fatalf("quantity: %v", quantity)
+	}
+
+	for i := range expected {
+		if quantity[i] != expected[i] {
+			t.Errorf("expected: %v, actual: %v", expected[i], quantity[i])
+		}
+	}
+}
+
+func TestReadCURColumnNotFound(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := fmt.Sprintf("%s/cur.csv", dir)
+	if err := ioutil.WriteFile(file, []byte("lineItem/UsageAccountId,lineItem/UsageType\n123456789012,APN1-BoxUsage:c4.large\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := ReadCUR(file); err == nil || !strings.Contains(err.Error(), "column not found") {
+		t.Errorf("expected column not found: %v", err)
+	}
+}
+
+func TestReplaceAccount(t *testing.T) {
+	cached := []Quantity{
+		{AccountID: "123456789012", UsageType: "APN1-BoxUsage:c4.large", Date: "2019-08", InstanceNum: 3},
+		{AccountID: "210987654321", UsageType: "APN1-BoxUsage:c4.large", Date: "2019-08", InstanceNum: 5},
+	}
+
+	imported := []Quantity{
+		{AccountID: "123456789012", UsageType: "APN1-BoxUsage:m4.large", Date: "2019-08", InstanceNum: 1},
+	}
+
+	out := ReplaceAccount(cached, imported)
+	if len(out) != 2 {
+		t.Fatalf("replaced: %v", out)
+	}
+
+	for _, q := range out {
+		if q.AccountID == "123456789012" && q.UsageType != "APN1-BoxUsage:m4.large" {
+			t.Errorf("not replaced: %v", q)
+		}
+
+		if q.AccountID == "210987654321" && q.InstanceNum != 5 {
+			t.Errorf("not kept: %v", q)
+		}
+	}
+}
+
+func TestPlatform(t *testing.T) {
+	cases := []struct {
+		OperatingSystem string
+		PreInstalledSW  string
+		LicenseModel    string
+		Platform        string
+	}{
+		{"Linux", "NA", "No License required", "Linux/UNIX"},
+		{"Linux", "SQL Web", "License included", "Linux with SQL Web"},
+		{"Windows", "NA", "License included", "Windows"},
+		{"Windows", "SQL Ent", "License included", "Windows with SQL Enterprise"},
+		{"Windows", "NA", "Bring your own license", "Windows (BYOL)"},
+		{"RHEL", "NA", "No License required", "Red Hat Enterprise Linux"},
+		{"SUSE", "NA", "No License required", "SUSE Linux"},
+	}
+
+	for _, c := range cases {
+		p := Platform(c.OperatingSystem, c.PreInstalledSW, c.LicenseModel)
+		if p != c.Platform {
+			t.Errorf("expected: %v, actual: %v", c.Platform, p)
+		}
+	}
+}
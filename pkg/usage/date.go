@@ -1,6 +1,9 @@
 package usage
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Date struct {
 	Start string
@@ -11,6 +14,19 @@ func (d Date) YYYYMM() string {
 	return d.Start[:7]
 }
 
+// NewDate returns the month of yyyymm (2006-01).
+func NewDate(yyyymm string) (Date, error) {
+	m, err := time.Parse("2006-01", yyyymm)
+	if err != nil {
+		return Date{}, fmt.Errorf("parse %s: %v", yyyymm, err)
+	}
+
+	return Date{
+		Start: m.Format("2006-01") + "-01",
+		End:   m.AddDate(0, 1, 0).Format("2006-01") + "-01",
+	}, nil
+}
+
 func Last12Months() []Date {
 	month := make([]time.Time, 0)
 	for i := 1; i < 13; i++ {
@@ -0,0 +1,83 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/itsubaki/hermes/pkg/cache"
+)
+
+// Dimension is the linked accounts and usage types of a month.
+type Dimension struct {
+	Account   []Account `json:"account"`
+	UsageType []string  `json:"usage_type"`
+}
+
+// FetchDimension returns the linked accounts and usage types of date.
+func FetchDimension(c CostExplorer, date Date) (Dimension, error) {
+	account, err := FetchLinkedAccount(c, date.Start, date.End)
+	if err != nil {
+		return Dimension{}, fmt.Errorf("get linked account: %v", err)
+	}
+
+	usageType, err := FetchUsageType(c, date.Start, date.End)
+	if err != nil {
+		return Dimension{}, fmt.Errorf("get usage type: %v", err)
+	}
+
+	return Dimension{Account: account, UsageType: usageType}, nil
+}
+
+// SerializeDimension writes the dimension values of date to {dir}/dimension/{YYYY-MM}.out.
+func SerializeDimension(dir string, date Date, d Dimension, opt cache.Option) error {
+	path := fmt.Sprintf("%s/dimension", dir)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		os.MkdirAll(path, os.ModePerm)
+	}
+
+	if len(opt.Source) < 1 {
+		opt.Source = "costexplorer"
+	}
+
+	bytes, err := cache.Encode(SchemaVersion, d, opt)
+	if err != nil {
+		return fmt.Errorf("encode: %v", err)
+	}
+
+	file := fmt.Sprintf("%s/%s.out", path, date.YYYYMM())
+	if err := cache.WriteFile(file, bytes); err != nil {
+		return fmt.Errorf("write file: %v", err)
+	}
+
+	return nil
+}
+
+// DeserializeDimension returns the cached dimension values of date. ok is false when they are not cached.
+func DeserializeDimension(dir string, date Date) (d Dimension, ok bool, err error) {
+	file := fmt.Sprintf("%s/dimension/%s.out", dir, date.YYYYMM())
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return Dimension{}, false, nil
+	}
+
+	read, err := ioutil.ReadFile(file)
+	if err != nil {
+		return Dimension{}, false, fmt.Errorf("read %s: %v", file, err)
+	}
+
+	h, data, err := cache.Decode(read)
+	if err != nil {
+		return Dimension{}, false, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	if err := cache.Check(h, SchemaVersion); err != nil {
+		return Dimension{}, false, fmt.Errorf("decode %s: %v", file, err)
+	}
+
+	if err := json.Unmarshal(data, &d); err != nil {
+		return Dimension{}, false, fmt.Errorf("unmarshal %s: %v", file, err)
+	}
+
+	return d, true, nil
+}
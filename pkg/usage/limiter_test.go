@@ -0,0 +1,33 @@
+package usage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(100, 1)
+
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		l.Wait()
+	}
+
+	if d := time.Since(start); d < 90*time.Millisecond {
+		t.Errorf("not limited: %v", d)
+	}
+}
+
+func TestLimiterNil(t *testing.T) {
+	var l *Limiter
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		l.Wait()
+		NewLimiter(0, 1).Wait()
+	}
+
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("limited: %v", d)
+	}
+}
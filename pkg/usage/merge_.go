@@ -6,12 +6,14 @@ func Merge(q []Quantity) []Quantity {
 	merged := make(map[string]Quantity)
 	for i := range q {
 		hash := fmt.Sprintf(
-			"%s%s%s%s%s%s",
+			"%s%s%s%s%s%s%s%s",
 			q[i].AccountID,
 			q[i].UsageType,
 			q[i].Platform,
 			q[i].CacheEngine,
 			q[i].DatabaseEngine,
+			q[i].Tenancy,
+			q[i].AvailabilityZone,
 			q[i].Date,
 		)
 
@@ -22,16 +24,18 @@ func Merge(q []Quantity) []Quantity {
 		}
 
 		merged[hash] = Quantity{
-			AccountID:      q[i].AccountID,
-			Description:    q[i].Description,
-			Region:         q[i].Region,
-			UsageType:      q[i].UsageType,
-			Platform:       q[i].Platform,
-			CacheEngine:    q[i].CacheEngine,
-			DatabaseEngine: q[i].DatabaseEngine,
-			Date:           q[i].Date,
-			InstanceHour:   q[i].InstanceHour + v.InstanceHour,
-			InstanceNum:    q[i].InstanceNum + v.InstanceNum,
+			AccountID:        q[i].AccountID,
+			Description:      q[i].Description,
+			Region:           q[i].Region,
+			UsageType:        q[i].UsageType,
+			Platform:         q[i].Platform,
+			CacheEngine:      q[i].CacheEngine,
+			DatabaseEngine:   q[i].DatabaseEngine,
+			Tenancy:          q[i].Tenancy,
+			AvailabilityZone: q[i].AvailabilityZone,
+			Date:             q[i].Date,
+			InstanceHour:     q[i].InstanceHour + v.InstanceHour,
+			InstanceNum:      q[i].InstanceNum + v.InstanceNum,
 		}
 	}
 
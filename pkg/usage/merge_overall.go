@@ -6,11 +6,12 @@ func MergeOverall(q []Quantity) []Quantity {
 	merged := make(map[string]Quantity)
 	for i := range q {
 		hash := fmt.Sprintf(
-			"%s%s%s%s%s",
+			"%s%s%s%s%s%s",
 			q[i].UsageType,
 			q[i].Platform,
 			q[i].CacheEngine,
 			q[i].DatabaseEngine,
+			q[i].Tenancy,
 			q[i].Date,
 		)
 
@@ -22,6 +23,7 @@ func MergeOverall(q []Quantity) []Quantity {
 				Platform:       q[i].Platform,
 				CacheEngine:    q[i].CacheEngine,
 				DatabaseEngine: q[i].DatabaseEngine,
+				Tenancy:        q[i].Tenancy,
 				Date:           q[i].Date,
 				InstanceHour:   q[i].InstanceHour,
 				InstanceNum:    q[i].InstanceNum,
@@ -35,6 +37,7 @@ func MergeOverall(q []Quantity) []Quantity {
 			Platform:       q[i].Platform,
 			CacheEngine:    q[i].CacheEngine,
 			DatabaseEngine: q[i].DatabaseEngine,
+			Tenancy:        q[i].Tenancy,
 			Date:           q[i].Date,
 			InstanceHour:   q[i].InstanceHour + v.InstanceHour,
 			InstanceNum:    q[i].InstanceNum + v.InstanceNum,
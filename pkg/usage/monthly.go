@@ -9,12 +9,14 @@ func Monthly(q []Quantity) map[string][]Quantity {
 	monthly := make(map[string][]Quantity)
 	for i := range q {
 		hash := fmt.Sprintf(
-			"%s%s%s%s%s",
+			"%s%s%s%s%s%s%s",
 			q[i].AccountID,
 			q[i].UsageType,
 			q[i].Platform,
 			q[i].CacheEngine,
 			q[i].DatabaseEngine,
+			q[i].Tenancy,
+			q[i].AvailabilityZone,
 		)
 
 		monthly[hash] = append(monthly[hash], q[i])
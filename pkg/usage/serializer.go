@@ -6,9 +6,41 @@ import (
 	"io/ioutil"
 	"os"
 	"sort"
+
+	"github.com/itsubaki/hermes/pkg/cache"
 )
 
-func Serialize(dir string, quantity []Quantity) error {
+// SchemaVersion is the version of []Quantity written by Serialize.
+const SchemaVersion = 1
+
+func Serialize(dir string, date Date, quantity []Quantity) error {
+	return SerializeWithOption(dir, date, quantity, cache.Option{})
+}
+
+func SerializeWithOption(dir string, date Date, quantity []Quantity, opt cache.Option) error {
+	path := fmt.Sprintf("%s/usage", dir)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		os.MkdirAll(path, os.ModePerm)
+	}
+
+	file := fmt.Sprintf("%s/%s.out", path, date.YYYYMM())
+	if _, err := os.Stat(file); !os.IsNotExist(err) && !opt.Overwrite {
+		return nil
+	}
+
+	if len(opt.Source) < 1 {
+		opt.Source = "costexplorer"
+	}
+
+	bytes, err := cache.Encode(SchemaVersion, quantity, opt)
+	if err != nil {
+		return fmt.Errorf("encode: %v", err)
+	}
+
+	if err := cache.WriteFile(file, bytes); err != nil {
+		return fmt.Errorf("write file: %v", err)
+	}
+
 	return nil
 }
 
@@ -25,9 +57,9 @@ func Deserialize(dir string, date []Date) ([]Quantity, error) {
 			return []Quantity{}, fmt.Errorf("read %s: %v", file, err)
 		}
 
-		var q []Quantity
-		if err := json.Unmarshal(read, &q); err != nil {
-			return []Quantity{}, fmt.Errorf("unmarshal: %v", err)
+		q, err := Decode(read)
+		if err != nil {
+			return []Quantity{}, fmt.Errorf("decode %s: %v", file, err)
 		}
 
 		quantity = append(quantity, q...)
@@ -40,3 +72,28 @@ func Deserialize(dir string, date []Date) ([]Quantity, error) {
 
 	return quantity, nil
 }
+
+// Decode returns the quantities of a cache file, migrating older schema versions.
+func Decode(b []byte) ([]Quantity, error) {
+	h, data, err := cache.Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cache.Check(h, SchemaVersion); err != nil {
+		return nil, err
+	}
+
+	var q []Quantity
+	if err := json.Unmarshal(data, &q); err != nil {
+		return nil, fmt.Errorf("unmarshal: %v", err)
+	}
+
+	return migrate(h.SchemaVersion, q), nil
+}
+
+// migrate upgrades quantities read from schema version v to SchemaVersion.
+func migrate(v int, quantity []Quantity) []Quantity {
+	// 0 -> 1: bare json array without header. Quantity is unchanged.
+	return quantity
+}
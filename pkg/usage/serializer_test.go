@@ -2,9 +2,77 @@ package usage
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
+
+	"github.com/itsubaki/hermes/pkg/cache"
 )
 
+func TestSerialize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	date := Date{Start: "2019-08-01", End: "2019-09-01"}
+	quantity := []Quantity{
+		{
+			AccountID:   "123456789012",
+			Region:      "ap-northeast-1",
+			UsageType:   "APN1-BoxUsage:c4.large",
+			Platform:    "Linux/UNIX",
+			Date:        "2019-08",
+			InstanceNum: 1,
+		},
+	}
+
+	if err := SerializeWithOption(dir, date, quantity, cache.Option{Compress: true}); err != nil {
+		t.Errorf("serialize: %v", err)
+	}
+
+	q, err := Deserialize(dir, []Date{date})
+	if err != nil {
+		t.Errorf("deserialize: %v", err)
+	}
+
+	if len(q) != 1 || q[0] != quantity[0] {
+		t.Errorf("expected: %v, actual: %v", quantity, q)
+	}
+}
+
+func TestSerializeDimension(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hermes")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	date := Date{Start: "2019-08-01", End: "2019-09-01"}
+	if _, ok, err := DeserializeDimension(dir, date); ok || err != nil {
+		t.Errorf("not cached: %v, %v", ok, err)
+	}
+
+	d := Dimension{
+		Account:   []Account{{ID: "123456789012", Description: "example"}},
+		UsageType: []string{"APN1-BoxUsage:c4.large"},
+	}
+
+	if err := SerializeDimension(dir, date, d, cache.Option{Compress: true}); err != nil {
+		t.Errorf("serialize: %v", err)
+	}
+
+	out, ok, err := DeserializeDimension(dir, date)
+	if !ok || err != nil {
+		t.Errorf("deserialize: %v, %v", ok, err)
+	}
+
+	if len(out.Account) != 1 || out.Account[0] != d.Account[0] || len(out.UsageType) != 1 || out.UsageType[0] != d.UsageType[0] {
+		t.Errorf("expected: %v, actual: %v", d, out)
+	}
+}
+
 func TestDeserialize(t *testing.T) {
 	usage, err := Deserialize("/var/tmp/hermes", Last12Months())
 	if err != nil {
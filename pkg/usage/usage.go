@@ -3,12 +3,14 @@ package usage
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"runtime"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/costexplorer"
 )
 
@@ -18,16 +20,18 @@ type Account struct {
 }
 
 type Quantity struct {
-	AccountID      string  `json:"account_id,omitempty"`
-	Description    string  `json:"description,omitempty"`
-	Region         string  `json:"region,omitempty"`
-	UsageType      string  `json:"usage_type"`
-	Platform       string  `json:"platform,omitempty"`
-	CacheEngine    string  `json:"cache_engine,omitempty"`
-	DatabaseEngine string  `json:"database_engine,omitempty"`
-	Date           string  `json:"date,omitempty"`
-	InstanceHour   float64 `json:"instance_hour,omitempty"`
-	InstanceNum    float64 `json:"instance_num"`
+	AccountID        string  `json:"account_id,omitempty"`
+	Description      string  `json:"description,omitempty"`
+	Region           string  `json:"region,omitempty"`
+	UsageType        string  `json:"usage_type"`
+	Platform         string  `json:"platform,omitempty"`
+	CacheEngine      string  `json:"cache_engine,omitempty"`
+	DatabaseEngine   string  `json:"database_engine,omitempty"`
+	Tenancy          string  `json:"tenancy,omitempty"`
+	AvailabilityZone string  `json:"availability_zone,omitempty"`
+	Date             string  `json:"date,omitempty"`
+	InstanceHour     float64 `json:"instance_hour,omitempty"`
+	InstanceNum      float64 `json:"instance_num"`
 }
 
 type GetQuantityInput struct {
@@ -61,42 +65,164 @@ func Sort(quantity []Quantity) {
 	sort.SliceStable(quantity, func(i, j int) bool { return quantity[i].AccountID < quantity[j].AccountID })
 }
 
-type FetchFunc func(start, end string, account Account, usageType []string) ([]Quantity, error)
+type FetchFunc func(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error)
 
 var FetchFuncList = []FetchFunc{
 	fetchBoxUsage,
 	fetchNodeUsage,
 	fetchInstanceUsage,
 	fetchMultiAZUsage,
+	fetchRedshiftNode,
+	fetchOpenSearchInstance,
+	fetchDynamoDBCapacity,
+	fetchMemoryDBNode,
+}
+
+type FetchInput struct {
+	Start      string
+	End        string
+	Client     CostExplorer
+	Parallel   int
+	Limiter    *Limiter
+	Budget     *Budget
+	MaxRetries int
+	Account    []Account // fetched from Start to End when empty
+	UsageType  []string  // fetched from Start to End when empty
+}
+
+// FetchError is a failed FetchFunc for an account.
+type FetchError struct {
+	AccountID string
+	Func      string
+	Err       error
+}
+
+func (e FetchError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.AccountID, e.Func, e.Err)
+}
+
+// PartialError lists the accounts and functions that failed.
+// Fetch returns it together with the quantities that were fetched.
+type PartialError struct {
+	Failed []FetchError
+}
+
+func (e *PartialError) Error() string {
+	msg := make([]string, 0)
+	for _, f := range e.Failed {
+		msg = append(msg, f.Error())
+	}
+
+	return fmt.Sprintf("%d requests failed: %s", len(e.Failed), strings.Join(msg, "; "))
 }
 
 func Fetch(start, end string) ([]Quantity, error) {
-	linkedAccount, err := fetchLinkedAccount(start, end)
-	if err != nil {
-		return nil, fmt.Errorf("get linked account: %v", err)
+	return FetchWithInput(&FetchInput{
+		Start: start,
+		End:   end,
+	})
+}
+
+// FetchWithInput runs FetchFuncList for each linked account with in.Parallel workers.
+// When some of them fail, the quantities of the others are returned with a *PartialError.
+func FetchWithInput(in *FetchInput) ([]Quantity, error) {
+	if in.Client == nil {
+		in.Client = NewCostExplorer()
 	}
 
-	usageType, err := fetchUsageType(start, end)
-	if err != nil {
-		return nil, fmt.Errorf("get usage type: %v", err)
+	if in.Parallel < 1 {
+		in.Parallel = 1
 	}
 
-	out := make([]Quantity, 0)
+	c := &Client{
+		CostExplorer: in.Client,
+		Limiter:      in.Limiter,
+		Budget:       in.Budget,
+		MaxRetries:   in.MaxRetries,
+	}
+
+	linkedAccount := in.Account
+	if len(linkedAccount) < 1 {
+		a, err := FetchLinkedAccount(c, in.Start, in.End)
+		if err != nil {
+			return nil, fmt.Errorf("get linked account: %v", err)
+		}
+
+		linkedAccount = a
+	}
+
+	usageType := in.UsageType
+	if len(usageType) < 1 {
+		u, err := FetchUsageType(c, in.Start, in.End)
+		if err != nil {
+			return nil, fmt.Errorf("get usage type: %v", err)
+		}
+
+		usageType = u
+	}
+
+	type job struct {
+		Index   int
+		Account Account
+		Func    FetchFunc
+	}
+
+	jobs := make([]job, 0)
 	for _, a := range linkedAccount {
 		for _, f := range FetchFuncList {
-			quantity, err := f(start, end, a, usageType)
-			if err != nil {
-				return nil, fmt.Errorf("get usage quantity: %v", err)
+			jobs = append(jobs, job{Index: len(jobs), Account: a, Func: f})
+		}
+	}
+
+	quantity := make([][]Quantity, len(jobs))
+	errs := make([]error, len(jobs))
+
+	queue := make(chan job)
+	var wg sync.WaitGroup
+	for i := 0; i < in.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range queue {
+				quantity[j.Index], errs[j.Index] = j.Func(c, in.Start, in.End, j.Account, usageType)
 			}
+		}()
+	}
+
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
 
-			out = append(out, quantity...)
+	out := make([]Quantity, 0)
+	failed := make([]FetchError, 0)
+	for i, j := range jobs {
+		if errs[i] != nil {
+			failed = append(failed, FetchError{
+				AccountID: j.Account.ID,
+				Func:      funcName(j.Func),
+				Err:       errs[i],
+			})
+			continue
 		}
+
+		out = append(out, quantity[i]...)
+	}
+
+	if len(failed) > 0 {
+		return out, &PartialError{Failed: failed}
 	}
 
 	return out, nil
 }
 
-func fetchBoxUsage(start, end string, account Account, usageType []string) ([]Quantity, error) {
+func funcName(f FetchFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(f).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func fetchBoxUsage(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
 	ut := make([]string, 0)
 	for i := range usageType {
 		if !strings.Contains(usageType[i], "BoxUsage") {
@@ -105,7 +231,7 @@ func fetchBoxUsage(start, end string, account Account, usageType []string) ([]Qu
 		ut = append(ut, usageType[i])
 	}
 
-	return fetchQuantity(&GetQuantityInput{
+	return fetchQuantity(c, &GetQuantityInput{
 		AccountID:   account.ID,
 		Description: account.Description,
 		Dimension:   "PLATFORM",
@@ -115,16 +241,17 @@ func fetchBoxUsage(start, end string, account Account, usageType []string) ([]Qu
 	})
 }
 
-func fetchNodeUsage(start, end string, account Account, usageType []string) ([]Quantity, error) {
+// fetchNodeUsage returns the usage of ElastiCache nodes (APN1-NodeUsage:cache.r5.large) by cache engine.
+func fetchNodeUsage(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
 	ut := make([]string, 0)
 	for i := range usageType {
-		if !strings.Contains(usageType[i], "NodeUsage") {
+		if !strings.Contains(usageType[i], "NodeUsage") || strings.Contains(usageType[i], MemoryDBNodeUsage) {
 			continue
 		}
 		ut = append(ut, usageType[i])
 	}
 
-	return fetchQuantity(&GetQuantityInput{
+	return fetchQuantity(c, &GetQuantityInput{
 		AccountID:   account.ID,
 		Description: account.Description,
 		Dimension:   "CACHE_ENGINE",
@@ -134,7 +261,7 @@ func fetchNodeUsage(start, end string, account Account, usageType []string) ([]Q
 	})
 }
 
-func fetchInstanceUsage(start, end string, account Account, usageType []string) ([]Quantity, error) {
+func fetchInstanceUsage(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
 	ut := make([]string, 0)
 	for i := range usageType {
 		if !strings.Contains(usageType[i], "InstanceUsage") {
@@ -144,7 +271,7 @@ func fetchInstanceUsage(start, end string, account Account, usageType []string)
 		ut = append(ut, usageType[i])
 	}
 
-	return fetchQuantity(&GetQuantityInput{
+	return fetchQuantity(c, &GetQuantityInput{
 		AccountID:   account.ID,
 		Description: account.Description,
 		Dimension:   "DATABASE_ENGINE",
@@ -154,7 +281,7 @@ func fetchInstanceUsage(start, end string, account Account, usageType []string)
 	})
 }
 
-func fetchMultiAZUsage(start, end string, account Account, usageType []string) ([]Quantity, error) {
+func fetchMultiAZUsage(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
 	ut := make([]string, 0)
 	for i := range usageType {
 		if !strings.Contains(usageType[i], "Multi-AZUsage") {
@@ -164,7 +291,7 @@ func fetchMultiAZUsage(start, end string, account Account, usageType []string) (
 		ut = append(ut, usageType[i])
 	}
 
-	return fetchQuantity(&GetQuantityInput{
+	return fetchQuantity(c, &GetQuantityInput{
 		AccountID:   account.ID,
 		Description: account.Description,
 		Dimension:   "DATABASE_ENGINE",
@@ -174,95 +301,162 @@ func fetchMultiAZUsage(start, end string, account Account, usageType []string) (
 	})
 }
 
-func fetchQuantity(in *GetQuantityInput) ([]Quantity, error) {
-	and := make([]*costexplorer.Expression, 0)
-	and = append(and, &costexplorer.Expression{
-		Dimensions: &costexplorer.DimensionValues{
-			Key:    aws.String("LINKED_ACCOUNT"),
-			Values: []*string{aws.String(in.AccountID)},
-		},
+// fetchRedshiftNode returns the usage of Redshift nodes (APN1-Node:dc2.large).
+func fetchRedshiftNode(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
+	ut := make([]string, 0)
+	for i := range usageType {
+		if !strings.Contains(usageType[i], "Node:") {
+			continue
+		}
+
+		ut = append(ut, usageType[i])
+	}
+
+	return fetchQuantity(c, &GetQuantityInput{
+		AccountID:   account.ID,
+		Description: account.Description,
+		UsageType:   ut,
+		Start:       start,
+		End:         end,
 	})
+}
 
-	or := make([]*costexplorer.Expression, 0)
+// fetchOpenSearchInstance returns the usage of OpenSearch (Elasticsearch) instances (APN1-ESInstance:r5.large.search).
+func fetchOpenSearchInstance(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
+	ut := make([]string, 0)
+	for i := range usageType {
+		if !strings.Contains(usageType[i], "ESInstance:") {
+			continue
+		}
+
+		ut = append(ut, usageType[i])
+	}
+
+	return fetchQuantity(c, &GetQuantityInput{
+		AccountID:   account.ID,
+		Description: account.Description,
+		UsageType:   ut,
+		Start:       start,
+		End:         end,
+	})
+}
+
+// MemoryDBNodeUsage is the usage type of MemoryDB nodes (APN1-NodeUsage:db.r6g.large).
+const MemoryDBNodeUsage = "NodeUsage:db."
+
+// fetchMemoryDBNode returns the usage of MemoryDB nodes (APN1-NodeUsage:db.r6g.large) by usage type only.
+// Cost Explorer has no cache engine of MemoryDB usage.
+func fetchMemoryDBNode(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
+	ut := make([]string, 0)
+	for i := range usageType {
+		if !strings.Contains(usageType[i], MemoryDBNodeUsage) {
+			continue
+		}
+
+		ut = append(ut, usageType[i])
+	}
+
+	return fetchQuantity(c, &GetQuantityInput{
+		AccountID:   account.ID,
+		Description: account.Description,
+		UsageType:   ut,
+		Start:       start,
+		End:         end,
+	})
+}
+
+// fetchDynamoDBCapacity returns the usage of DynamoDB provisioned capacity (APN1-ReadCapacityUnit-Hrs, APN1-WriteCapacityUnit-Hrs).
+// The instance hours are capacity unit hours, and the instance num is the average capacity units in the month.
+func fetchDynamoDBCapacity(c CostExplorer, start, end string, account Account, usageType []string) ([]Quantity, error) {
+	ut := make([]string, 0)
+	for i := range usageType {
+		if !strings.Contains(usageType[i], "CapacityUnit-Hrs") {
+			continue
+		}
+
+		ut = append(ut, usageType[i])
+	}
+
+	return fetchQuantity(c, &GetQuantityInput{
+		AccountID:   account.ID,
+		Description: account.Description,
+		UsageType:   ut,
+		Start:       start,
+		End:         end,
+	})
+}
+
+// fetchQuantity returns the usage of in.UsageType broken down by in.Dimension, or by usage type only when it is empty.
+func fetchQuantity(c CostExplorer, in *GetQuantityInput) ([]Quantity, error) {
+	if len(in.UsageType) < 1 {
+		// no usage of this family. dont spend a request.
+		return []Quantity{}, nil
+	}
+
+	value := make([]*string, 0)
 	for i := range in.UsageType {
-		or = append(or, &costexplorer.Expression{
-			Dimensions: &costexplorer.DimensionValues{
-				Key:    aws.String("USAGE_TYPE"),
-				Values: []*string{aws.String(in.UsageType[i])},
-			},
+		value = append(value, aws.String(in.UsageType[i]))
+	}
+
+	groupBy := []*costexplorer.GroupDefinition{
+		{
+			Key:  aws.String("USAGE_TYPE"),
+			Type: aws.String("DIMENSION"),
+		},
+	}
+
+	if len(in.Dimension) > 0 {
+		groupBy = append(groupBy, &costexplorer.GroupDefinition{
+			Key:  aws.String(in.Dimension),
+			Type: aws.String("DIMENSION"),
 		})
 	}
 
 	input := costexplorer.GetCostAndUsageInput{
 		Metrics:     []*string{aws.String("UsageQuantity")},
 		Granularity: aws.String("MONTHLY"),
-		GroupBy: []*costexplorer.GroupDefinition{
-			{
-				Key:  aws.String("USAGE_TYPE"),
-				Type: aws.String("DIMENSION"),
-			},
-			{
-				Key:  aws.String(in.Dimension),
-				Type: aws.String("DIMENSION"),
-			},
-		},
+		GroupBy:     groupBy,
 		TimePeriod: &costexplorer.DateInterval{
 			Start: &in.Start,
 			End:   &in.End,
 		},
+		Filter: &costexplorer.Expression{
+			And: []*costexplorer.Expression{
+				{
+					Dimensions: &costexplorer.DimensionValues{
+						Key:    aws.String("LINKED_ACCOUNT"),
+						Values: []*string{aws.String(in.AccountID)},
+					},
+				},
+				{
+					Dimensions: &costexplorer.DimensionValues{
+						Key:    aws.String("USAGE_TYPE"),
+						Values: value,
+					},
+				},
+			},
+		},
 	}
 
-	if len(or) > 1 {
-		input.Filter = &costexplorer.Expression{
-			And: append(and, &costexplorer.Expression{Or: or}),
-		}
-	}
-
-	c := costexplorer.New(session.Must(session.NewSession()))
 	usage, err := c.GetCostAndUsage(&input)
 	if err != nil {
-		return []Quantity{}, fmt.Errorf("get cost and usage. or=%v: %v", or, err)
+		return []Quantity{}, fmt.Errorf("get cost and usage. usage_type=%v: %v", in.UsageType, err)
 	}
 
 	out := make([]Quantity, 0)
 	for _, r := range usage.ResultsByTime {
 		for _, g := range r.Groups {
-			amount := *g.Metrics["UsageQuantity"].Amount
-			if amount == "0" {
+			q, ok := newQuantity(in.Start, *g.Keys[0], *g.Metrics["UsageQuantity"].Amount)
+			if !ok {
 				continue
 			}
 
-			hrs, _ := strconv.ParseFloat(amount, 64)
-			month := strings.Split(in.Start, "-")[1]
-			num := hrs / float64(24*Days[month])
-
-			index := strings.LastIndex(in.Start, "-")
-			date := string(in.Start)[:index]
-			q := Quantity{
-				AccountID:    in.AccountID,
-				Description:  in.Description,
-				Date:         date,
-				UsageType:    *g.Keys[0],
-				InstanceHour: hrs,
-				InstanceNum:  num,
+			q.AccountID = in.AccountID
+			q.Description = in.Description
+			if len(g.Keys) > 1 {
+				setDimension(&q, in.Dimension, *g.Keys[1])
 			}
 
-			if in.Dimension == "PLATFORM" {
-				q.Platform = *g.Keys[1]
-			}
-			if in.Dimension == "CACHE_ENGINE" {
-				q.CacheEngine = *g.Keys[1]
-			}
-			if in.Dimension == "DATABASE_ENGINE" {
-				q.DatabaseEngine = *g.Keys[1]
-			}
-
-			region, ok := region[strings.Split(q.UsageType, "-")[0]]
-			if !ok {
-				continue
-			}
-			q.Region = region
-
 			out = append(out, q)
 		}
 	}
@@ -270,46 +464,79 @@ func fetchQuantity(in *GetQuantityInput) ([]Quantity, error) {
 	return out, nil
 }
 
-func fetchUsageType(start, end string) ([]string, error) {
-	input := costexplorer.GetDimensionValuesInput{
-		Dimension: aws.String("USAGE_TYPE"),
-		TimePeriod: &costexplorer.DateInterval{
-			Start: &start,
-			End:   &end,
-		},
+// newQuantity returns amount hours of usageType in the month of start.
+// ok is false when amount is zero or the region of usageType is unknown.
+func newQuantity(start, usageType, amount string) (Quantity, bool) {
+	if amount == "0" {
+		return Quantity{}, false
 	}
 
-	c := costexplorer.New(session.Must(session.NewSession()))
-	val, err := c.GetDimensionValues(&input)
+	region, ok := region[strings.Split(usageType, "-")[0]]
+	if !ok {
+		return Quantity{}, false
+	}
+
+	hrs, _ := strconv.ParseFloat(amount, 64)
+	month := strings.Split(start, "-")[1]
+	num := hrs / float64(24*Days[month])
+
+	index := strings.LastIndex(start, "-")
+	date := string(start)[:index]
+	return Quantity{
+		Region:       region,
+		Date:         date,
+		UsageType:    usageType,
+		InstanceHour: hrs,
+		InstanceNum:  num,
+	}, true
+}
+
+// InstanceType returns the instance (node) type of the usage type of q. APN1-Node:dc2.large is dc2.large.
+// It is empty for usage without an instance type such as DynamoDB capacity.
+func (q Quantity) InstanceType() string {
+	if strings.LastIndex(q.UsageType, ":") < 0 {
+		return ""
+	}
+
+	return q.UsageType[strings.LastIndex(q.UsageType, ":")+1:]
+}
+
+func setDimension(q *Quantity, dimension, value string) {
+	if dimension == "PLATFORM" {
+		q.Platform = value
+	}
+	if dimension == "CACHE_ENGINE" {
+		q.CacheEngine = value
+	}
+	if dimension == "DATABASE_ENGINE" {
+		q.DatabaseEngine = value
+	}
+}
+
+// FetchUsageType returns the usage types from start to end.
+func FetchUsageType(c CostExplorer, start, end string) ([]string, error) {
+	val, err := fetchDimensionValues(c, "USAGE_TYPE", start, end)
 	if err != nil {
-		return []string{}, fmt.Errorf("get dimenstion value: %v", err)
+		return []string{}, err
 	}
 
 	out := make([]string, 0)
-	for _, d := range val.DimensionValues {
+	for _, d := range val {
 		out = append(out, *d.Value)
 	}
 
 	return out, nil
 }
 
-func fetchLinkedAccount(start, end string) ([]Account, error) {
-	input := costexplorer.GetDimensionValuesInput{
-		Dimension: aws.String("LINKED_ACCOUNT"),
-		TimePeriod: &costexplorer.DateInterval{
-			Start: &start,
-			End:   &end,
-		},
-	}
-
-	c := costexplorer.New(session.Must(session.NewSession()))
-	val, err := c.GetDimensionValues(&input)
+// FetchLinkedAccount returns the linked accounts from start to end.
+func FetchLinkedAccount(c CostExplorer, start, end string) ([]Account, error) {
+	val, err := fetchDimensionValues(c, "LINKED_ACCOUNT", start, end)
 	if err != nil {
-		return []Account{}, fmt.Errorf("get dimension values: %v", err)
+		return []Account{}, err
 	}
 
 	out := make([]Account, 0)
-	for _, v := range val.DimensionValues {
+	for _, v := range val {
 		out = append(out, Account{
 			ID:          *v.Value,
 			Description: *v.Attributes["description"],
@@ -318,3 +545,28 @@ func fetchLinkedAccount(start, end string) ([]Account, error) {
 
 	return out, nil
 }
+
+func fetchDimensionValues(c CostExplorer, dimension, start, end string) ([]*costexplorer.DimensionValuesWithAttributes, error) {
+	input := costexplorer.GetDimensionValuesInput{
+		Dimension: aws.String(dimension),
+		TimePeriod: &costexplorer.DateInterval{
+			Start: &start,
+			End:   &end,
+		},
+	}
+
+	out := make([]*costexplorer.DimensionValuesWithAttributes, 0)
+	for {
+		val, err := c.GetDimensionValues(&input)
+		if err != nil {
+			return nil, fmt.Errorf("get dimension values: %v", err)
+		}
+
+		out = append(out, val.DimensionValues...)
+		if val.NextPageToken == nil || len(*val.NextPageToken) < 1 {
+			return out, nil
+		}
+
+		input.NextPageToken = val.NextPageToken
+	}
+}
@@ -12,7 +12,7 @@ func TestUsageType(t *testing.T) {
 
 	merged := make([]string, 0)
 	for _, d := range Last12Months() {
-		usageType, err := fetchUsageType(d.Start, d.End)
+		usageType, err := FetchUsageType(NewCostExplorer(), d.Start, d.End)
 		if err != nil {
 			t.Errorf("get usage type: %v", err)
 		}